@@ -0,0 +1,141 @@
+package rebrandly
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchWorkers is the number of workers of a BatchExecutor when no
+// other number is given
+const DefaultBatchWorkers = 4
+
+// BatchResult holds the outcome of a single request of a batch
+type BatchResult struct {
+	// Position of the request at the input
+	Index int
+	// The request that was sent
+	Request Request
+	// The answer, as returned by the Sender
+	Answer interface{}
+	// The error, as returned by the Sender, or the error of the context when
+	// the request was not sent due to cancellation
+	Err error
+}
+
+// BatchProgress holds the progress of a batch, reported after each request
+type BatchProgress struct {
+	// Number of requests that were finished so far
+	Done int
+	// Total number of requests, -1 when unknown
+	Total int
+	// The result of the request that was just finished
+	Result BatchResult
+}
+
+// BatchExecutor sends many requests using a bounded number of concurrent
+// workers
+type BatchExecutor struct {
+	// The Sender for the requests
+	Sender Sender
+	// Number of concurrent workers, DefaultBatchWorkers when 0
+	Workers int
+	// When not nil, called after each request is finished. Calls are never
+	// concurrent.
+	Progress func(progress BatchProgress)
+}
+
+// batchJob is a single request that is handed to a worker
+type batchJob struct {
+	index   int
+	request Request
+}
+
+// Run sends requests, and returns their results at the same order.
+//
+// When ctx is cancelled, requests that were not sent yet are not sent, and
+// their results hold the error of ctx.
+func (e BatchExecutor) Run(ctx context.Context, requests []Request) []BatchResult {
+	queue := make(chan Request, len(requests))
+	for _, request := range requests {
+		queue <- request
+	}
+	close(queue)
+
+	results := e.run(ctx, queue, len(requests))
+	for request := range queue {
+		results = append(results, BatchResult{
+			Index:   len(results),
+			Request: request,
+			Err:     ctx.Err(),
+		})
+	}
+	return results
+}
+
+// RunChannel sends the requests that are read from requests until it is
+// closed or ctx is cancelled, and returns their results at the order they
+// were read.
+func (e BatchExecutor) RunChannel(ctx context.Context, requests <-chan Request) []BatchResult {
+	return e.run(ctx, requests, -1)
+}
+
+func (e BatchExecutor) run(ctx context.Context, requests <-chan Request,
+	total int) []BatchResult {
+
+	workers := e.Workers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+
+	var mu sync.Mutex
+	var results []BatchResult
+	done := 0
+
+	jobs := make(chan batchJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := BatchResult{Index: job.index, Request: job.request}
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+				} else {
+					result.Answer, result.Err = e.Sender.Send(ctx, job.request)
+				}
+
+				mu.Lock()
+				results[job.index] = result
+				done++
+				if e.Progress != nil {
+					e.Progress(BatchProgress{
+						Done:   done,
+						Total:  total,
+						Result: result,
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for index := 0; ; index++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case request, ok := <-requests:
+			if !ok {
+				break dispatch
+			}
+			mu.Lock()
+			results = append(results, BatchResult{Index: index, Request: request})
+			mu.Unlock()
+			jobs <- batchJob{index: index, request: request}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
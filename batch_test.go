@@ -0,0 +1,137 @@
+package rebrandly
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func batchRequests(t *testing.T, count int) []Request {
+	requests := make([]Request, count)
+	for i := range requests {
+		request, err := InitLinkDetails(strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests[i] = request
+	}
+	return requests
+}
+
+// batchLinkID returns the link ID of a request of batchRequests
+func batchLinkID(r Request) string {
+	return r.URL.Path[len("/v1/links/"):]
+}
+
+func TestBatchExecutorKeepsOrder(t *testing.T) {
+	requests := batchRequests(t, 20)
+	executor := BatchExecutor{
+		Workers: 5,
+		Sender: SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			// Earlier requests take longer, so they finish last
+			index, _ := strconv.Atoi(batchLinkID(r))
+			time.Sleep(time.Duration(20-index) * time.Millisecond)
+			return LinkRequest{ID: batchLinkID(r)}, nil
+		}),
+	}
+
+	results := executor.Run(context.Background(), requests)
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Err != nil {
+			t.Errorf("Result #%d: got index %d, error %v", i, result.Index, result.Err)
+		}
+		link, ok := result.Answer.(LinkRequest)
+		if !ok || link.ID != strconv.Itoa(i) {
+			t.Errorf("Result #%d: got answer %v", i, result.Answer)
+		}
+	}
+}
+
+func TestBatchExecutorCancel(t *testing.T) {
+	requests := batchRequests(t, 50)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sent int32
+	executor := BatchExecutor{
+		Workers: 2,
+		Sender: SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			if atomic.AddInt32(&sent, 1) == 3 {
+				cancel()
+			}
+			return LinkRequest{ID: batchLinkID(r)}, nil
+		}),
+	}
+
+	results := executor.Run(ctx, requests)
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+	cancelled := 0
+	for i, result := range results {
+		if result.Index != i || batchLinkID(result.Request) != strconv.Itoa(i) {
+			t.Errorf("Result #%d: got index %d, request %s", i, result.Index,
+				result.Request.URL.Path)
+		}
+		switch {
+		case result.Err == context.Canceled:
+			cancelled++
+			if result.Answer != nil {
+				t.Errorf("Result #%d: cancelled with answer %v", i, result.Answer)
+			}
+		case result.Err != nil:
+			t.Errorf("Result #%d: unexpected error %v", i, result.Err)
+		case result.Answer == nil:
+			t.Errorf("Result #%d: missing answer", i)
+		}
+	}
+	if int(atomic.LoadInt32(&sent))+cancelled != len(requests) {
+		t.Errorf("Sent %d and cancelled %d of %d requests", sent, cancelled,
+			len(requests))
+	}
+	if cancelled == 0 {
+		t.Errorf("Expected requests to be cancelled")
+	}
+}
+
+func TestBatchExecutorProgress(t *testing.T) {
+	requests := batchRequests(t, 30)
+	var inProgress int32
+	var mu sync.Mutex
+	var done []int
+	executor := BatchExecutor{
+		Workers: 8,
+		Sender: SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			return nil, nil
+		}),
+		Progress: func(progress BatchProgress) {
+			if atomic.AddInt32(&inProgress, 1) != 1 {
+				t.Errorf("Progress was called concurrently")
+			}
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			done = append(done, progress.Done)
+			mu.Unlock()
+			if progress.Total != len(requests) {
+				t.Errorf("Total: got %d", progress.Total)
+			}
+			atomic.AddInt32(&inProgress, -1)
+		},
+	}
+
+	executor.Run(context.Background(), requests)
+	if len(done) != len(requests) {
+		t.Fatalf("Expected %d progress calls, got %d", len(requests), len(done))
+	}
+	for i, d := range done {
+		if d != i+1 {
+			t.Errorf("Progress #%d: got Done %d", i, d)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package rebrandly
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are the TTLs of ResponseCache when none are given. Only
+// requests of these actions are cached.
+var DefaultCacheTTLs = map[ActionTypes]time.Duration{
+	ActionTypeLinkDetails:   time.Minute,
+	ActionTypeDomainDetails: 10 * time.Minute,
+	ActionTypeDomainList:    10 * time.Minute,
+	ActionTypeDommainCount:  10 * time.Minute,
+}
+
+// CacheEntry holds a single cached answer
+type CacheEntry struct {
+	Answer    interface{}
+	ExpiresAt time.Time
+}
+
+// CacheBackend stores the entries of a ResponseCache. Implementations must
+// be safe for concurrent use.
+type CacheBackend interface {
+	// Get returns the entry of key, if it exists
+	Get(key string) (CacheEntry, bool)
+	// Set stores entry as key
+	Set(key string, entry CacheEntry)
+	// Delete removes the entry of key, if it exists
+	Delete(key string)
+	// DeletePrefix removes all the entries with keys that start with prefix
+	DeletePrefix(prefix string)
+}
+
+// lruItem is the value of the list elements of LRUCache
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// LRUCache is an in-memory CacheBackend that holds up to a fixed number of
+// entries, evicting the least recently used ones
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewLRUCache returns an empty LRUCache that holds up to size entries
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get implements the CacheBackend interface
+func (c *LRUCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruItem).entry, true
+}
+
+// Set implements the CacheBackend interface
+func (c *LRUCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruItem{key: key, entry: entry})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Delete implements the CacheBackend interface
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// DeletePrefix implements the CacheBackend interface
+func (c *LRUCache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Len returns the number of entries
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// ResponseCache caches the answers of GET requests, keyed by their namespace,
+// method, URL and headers, for a TTL that is set for each ActionTypes.
+//
+// Link entries are invalidated when a link is created, updated or deleted
+// through the Middleware of a ResponseCache with the same namespace. Changes
+// made by other clients are seen only after the TTL expires.
+//
+// IMPORTANT: The API key is not part of the cache key. Senders of different
+// API keys (accounts) must use different namespaces, such as the ones that
+// are returned by CacheNamespace, otherwise the links of one account are
+// served to another.
+//
+// Note: Cached answers are shared, and must not be modified by callers.
+type ResponseCache struct {
+	// Where entries are stored, might be shared by several ResponseCache
+	Backend CacheBackend
+	// Separates the entries of different accounts at the same Backend
+	Namespace string
+	// TTL of each action. Actions without a positive TTL are not cached.
+	TTL map[ActionTypes]time.Duration
+}
+
+// NewResponseCache returns a ResponseCache of namespace on top of backend,
+// using ttl, or DefaultCacheTTLs when ttl is nil
+func NewResponseCache(backend CacheBackend, namespace string,
+	ttl map[ActionTypes]time.Duration) *ResponseCache {
+
+	if ttl == nil {
+		ttl = DefaultCacheTTLs
+	}
+	return &ResponseCache{Backend: backend, Namespace: namespace, TTL: ttl}
+}
+
+// CacheNamespace returns a namespace for ResponseCache that is derived from
+// identity, such as an API key, without exposing it
+func CacheNamespace(identity string) string {
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:16])
+}
+
+// urlPrefix returns the prefix of the cache keys of GET requests to u
+func (c *ResponseCache) urlPrefix(u string) string {
+	return c.Namespace + "\n" + http.MethodGet + " " + u
+}
+
+// cacheKey returns the cache key of r. Headers of r, except for the
+// traceparent that differs on every request, are hashed, so their values are
+// not kept by the backend.
+func (c *ResponseCache) cacheKey(r Request) string {
+	key := c.Namespace + "\n" + r.Method + " " + r.URL.String() + "\n"
+	header := r.Header.Clone()
+	header.Del(TraceParentHeader)
+	if len(header) == 0 {
+		return key
+	}
+	hash := sha256.New()
+	for _, name := range sortedHeaderNames(header) {
+		hash.Write([]byte(http.CanonicalHeaderKey(name) + ": " +
+			strings.Join(header[name], ",") + "\n"))
+	}
+	return key + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Invalidate removes the entries that might be changed by r: the details of
+// the link of an update or delete, and all link lists and counts
+func (c *ResponseCache) Invalidate(r Request) {
+	switch r.ActionType {
+	case ActionTypeLinkCreate, ActionTypeLinkUpdate, ActionTypeLinkDelete:
+	default:
+		return
+	}
+
+	links := r.URL
+	links.RawQuery, links.Fragment = "", ""
+	if r.ActionType != ActionTypeLinkCreate {
+		c.Backend.DeletePrefix(c.urlPrefix(links.String()) + "\n")
+		links.Path = path.Dir(links.Path)
+	}
+	prefix := c.urlPrefix(links.String())
+	c.Backend.DeletePrefix(prefix + "?")
+	c.Backend.DeletePrefix(prefix + "/count")
+}
+
+// Middleware returns a Middleware that answers cached requests from c, and
+// invalidates link entries on changes
+func (c *ResponseCache) Middleware() Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			ttl := c.TTL[r.ActionType]
+			if r.Method != http.MethodGet || ttl <= 0 {
+				answer, err := next.Send(ctx, r)
+				if err == nil {
+					c.Invalidate(r)
+				}
+				return answer, err
+			}
+
+			key := c.cacheKey(r)
+			if entry, ok := c.Backend.Get(key); ok {
+				if time.Now().Before(entry.ExpiresAt) {
+					return entry.Answer, nil
+				}
+				c.Backend.Delete(key)
+			}
+			answer, err := next.Send(ctx, r)
+			if err == nil {
+				c.Backend.Set(key, CacheEntry{
+					Answer:    answer,
+					ExpiresAt: time.Now().Add(ttl),
+				})
+			}
+			return answer, err
+		})
+	}
+}
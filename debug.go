@@ -0,0 +1,90 @@
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MaskAPIKey returns apiKey with all but its last 4 characters masked
+func MaskAPIKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return strings.Repeat("*", len(apiKey))
+	}
+	return strings.Repeat("*", len(apiKey)-4) + apiKey[len(apiKey)-4:]
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// debugRequest creates the HTTP request of r with the API key masked, and
+// sensitive headers and query parameters redacted
+func (r Request) debugRequest(apiKey string) (*http.Request, []byte, error) {
+	r.URL = RedactURL(r.URL)
+	req, body, err := r.httpRequest(context.Background(), MaskAPIKey(apiKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header = RedactHeader(req.Header)
+	req.Header.Set("apikey", MaskAPIKey(apiKey))
+	return req, body, nil
+}
+
+// sortedHeaderNames returns the names of header in a stable order
+func sortedHeaderNames(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Curl returns a curl command that sends the same HTTP request as
+// SendRequest does with apiKey, but with the API key masked, so it can be
+// shared safely
+func (r Request) Curl(apiKey string) (string, error) {
+	req, body, err := r.debugRequest(apiKey)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "curl -X %s %s", req.Method, shellQuote(req.URL.String()))
+	for _, name := range sortedHeaderNames(req.Header) {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(&buf, " \\\n  -H %s", shellQuote(name+": "+value))
+		}
+	}
+	if len(body) > 0 {
+		fmt.Fprintf(&buf, " \\\n  --data-raw %s", shellQuote(string(body)))
+	}
+	return buf.String(), nil
+}
+
+// DumpHTTP returns the raw HTTP/1.1 request that SendRequest sends with
+// apiKey, but with the API key masked, so it can be shared safely
+func (r Request) DumpHTTP(apiKey string) (string, error) {
+	req, body, err := r.debugRequest(apiKey)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", req.URL.Host)
+	for _, name := range sortedHeaderNames(req.Header) {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+		}
+	}
+	if len(body) > 0 {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(body))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.String(), nil
+}
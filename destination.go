@@ -0,0 +1,138 @@
+package rebrandly
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// TrackingParams holds the query parameters that are removed by
+// NormalizeDestination when StripTrackingParams is set.
+// Parameters that start with utm_ are always considered tracking parameters.
+var TrackingParams = []string{
+	"fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "yclid",
+}
+
+// NormalizeOptions holds which differences between destinations are ignored
+// when comparing them
+type NormalizeOptions struct {
+	// Treat http and https as the same
+	IgnoreScheme bool
+	// Treat a path with and without a trailing slash as the same
+	IgnoreTrailingSlash bool
+	// Treat query parameters at any order as the same
+	SortQuery bool
+	// Remove tracking parameters such as utm_source
+	StripTrackingParams bool
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "utm_") {
+		return true
+	}
+	for _, param := range TrackingParams {
+		if name == param {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeDestination returns destination in a form that is equal for
+// destinations that differ only by what options ignore.
+// The host is always lower cased. A destination that cannot be parsed is
+// returned as is.
+func NormalizeDestination(destination string, options NormalizeOptions) string {
+	u, err := url.Parse(strings.TrimSpace(destination))
+	if err != nil || u.Host == "" {
+		return destination
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if options.IgnoreScheme {
+		u.Scheme = "https"
+	}
+	if options.IgnoreTrailingSlash {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = ""
+	}
+	if options.StripTrackingParams {
+		u.RawQuery = stripQuery(u.RawQuery)
+	}
+	if options.SortQuery {
+		// Encode sorts by key
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String()
+}
+
+// stripQuery removes tracking parameters from rawQuery, keeping the order of
+// the rest of the parameters
+func stripQuery(rawQuery string) string {
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		name := strings.SplitN(pair, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if pair != "" && !isTrackingParam(name) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// FindLinksByDestination walks over the active links of domainID (or all
+// domains when empty), and returns the ones that point to destination, after
+// both are normalized using options.
+func FindLinksByDestination(ctx context.Context, sender Sender, destination,
+	domainID string, options NormalizeOptions) (LinkRequestList, error) {
+
+	var found LinkRequestList
+	wanted := NormalizeDestination(destination, options)
+	err := WalkAllLinks(ctx, sender, domainID, func(link LinkRequest) error {
+		if link.Status == LinkStatusTrashed {
+			return nil
+		}
+		if NormalizeDestination(link.Destination, options) == wanted {
+			found = append(found, link)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// GetOrCreateLink returns an existing active link at the domain of input that
+// points to the destination of input, or creates a new link from input when
+// there is none.
+//
+// The returned bool is true when the link was created.
+func GetOrCreateLink(ctx context.Context, sender Sender, input LinkCreateInput,
+	options NormalizeOptions) (LinkRequest, bool, error) {
+
+	domainID := ""
+	if input.Domain != nil {
+		domainID = input.Domain.ID
+	}
+	links, err := FindLinksByDestination(ctx, sender, input.Destination,
+		domainID, options)
+	if err != nil {
+		return LinkRequest{}, false, err
+	}
+	for _, link := range links {
+		if domainID != "" || link.Domain.FullName == DefaultDomainName {
+			return link, false, nil
+		}
+	}
+
+	request, err := InitCreateLinkInput(input)
+	if err != nil {
+		return LinkRequest{}, false, err
+	}
+	link, err := sendLinkRequest(ctx, sender, request)
+	if err != nil {
+		return LinkRequest{}, false, err
+	}
+	return link, true, nil
+}
@@ -17,6 +17,10 @@ functions implementation:
 The Ex suffix stand for extended, and provides a means to fully control the
 JSON that is going to be sent out.
 
+For links, `InitCreateLinkInput` and `InitUpdateLinkInput` use the
+`LinkCreateInput` and `LinkUpdateInput` structs, that send only the fields that
+were set, without the fields that are managed by rebrandly.
+
 The `InitXxx` function, requires only the minimal parameters that are usually
 mandatory by the API in order to function, but does not add extra fields,
 focusing only on the task rather then the extra functionality.
@@ -36,6 +40,18 @@ Therefore when using the API it is important to realize that even when the API r
 
 As convinience, the function `IsErrorStruct` takes such struct and determines if it is a REST error or not.
 
+Responses that are not decoded into any of the REST errors are returned as
+`ResponseError`, that holds the status code and body of the response, and
+wraps the original error (such as `Unsupported StatusCode: 429`, or a JSON
+syntax error of a body that is not JSON). Every 5xx response is returned as
+`ServerErrorResponse`, with its `StatusCode` set, even when its body is not
+JSON, in which case the body is used as the message.
+`StatusCode(err)` returns the HTTP status code of all of these errors.
+
+Note: Before `ResponseError`, these errors were returned as is, and 5xx
+responses other than 500, 502, 503 and 504 were returned as
+`Unsupported StatusCode` errors.
+
 Basic Usage
 -----------
 
@@ -57,5 +73,21 @@ with full details about it.
 If there was an error returned by the server, then an error struct will be
 returned.
 
-Any other type of error will be placed on the `err` variable instead.*/
+Any other type of error will be placed on the `err` variable instead.
+
+Senders and middleware
+----------------------
+
+The higher level operations of the package, such as `ExportLinks` or
+`UpdateLink`, send their requests using the `Sender` interface.
+`APIKey` is the `Sender` that sends requests directly to rebrandly:
+
+    sender := rebrandly.APIKey("1234567890")
+
+`Chain` wraps a `Sender` with middleware, that can look at and change every
+request before it is sent, and the answer or error that is returned:
+
+    sender := rebrandly.Chain(rebrandly.APIKey("1234567890"),
+       rebrandly.WithHeader("workspace", "my-workspace"))
+*/
 package rebrandly
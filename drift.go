@@ -0,0 +1,120 @@
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DriftKind holds an "enum" of the kinds of drift
+type DriftKind string
+
+// Enumeration values for DriftKind
+const (
+	// The link is part of the desired state, but not found at the account
+	DriftKindMissing DriftKind = "missing"
+	// The link is found at a managed domain, but not part of the desired state
+	DriftKindExtra DriftKind = "extra"
+	// The link exists at both, but with different fields
+	DriftKindChanged DriftKind = "changed"
+)
+
+// driftFields are the fields that are checked for DriftKindChanged
+var driftFields = map[string]bool{
+	"destination":       true,
+	"title":             true,
+	"forwardParameters": true,
+}
+
+// DriftEntry holds the drift of a single link
+type DriftEntry struct {
+	Kind DriftKind `json:"kind"`
+	// Full name of the domain of the link
+	Domain string `json:"domain"`
+	// The keyword section of the link
+	SlashTag string `json:"slashtag"`
+	// The live link, empty for DriftKindMissing
+	Live LinkRequest `json:"live"`
+	// The fields that differ for DriftKindChanged
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// DriftReport holds the differences between a desired state and the live
+// account
+type DriftReport struct {
+	Entries []DriftEntry `json:"entries"`
+}
+
+// HasDrift returns true when the live account differs from the desired state
+func (r DriftReport) HasDrift() bool {
+	return len(r.Entries) > 0
+}
+
+// WriteJSON writes the report as an indented JSON document into w
+func (r DriftReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human readable form into w
+func (r DriftReport) WriteText(w io.Writer) error {
+	var buf bytes.Buffer
+	if !r.HasDrift() {
+		buf.WriteString("No drift found\n")
+	}
+	for _, entry := range r.Entries {
+		fmt.Fprintf(&buf, "%-8s %s/%s\n", entry.Kind, entry.Domain, entry.SlashTag)
+		for _, change := range entry.Changes {
+			fmt.Fprintf(&buf, "         %s: live %q, desired %q\n", change.Field,
+				change.Old, change.New)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// DetectDrift compares the live links of the account with state without
+// changing anything.
+//
+// As with MakePlan, only the domains that are referred by state are checked.
+// Links are reported as changed when their destination, title or forward
+// parameters differ.
+func DetectDrift(ctx context.Context, sender Sender, state DesiredState) (DriftReport, error) {
+	var report DriftReport
+	plan, err := MakePlan(ctx, sender, state)
+	if err != nil {
+		return report, err
+	}
+
+	for _, item := range plan.Items {
+		entry := DriftEntry{
+			Domain:   item.Domain,
+			SlashTag: item.SlashTag,
+			Live:     item.Live,
+		}
+		switch item.Action {
+		case PlanActionCreate:
+			entry.Kind = DriftKindMissing
+		case PlanActionDelete:
+			entry.Kind = DriftKindExtra
+		case PlanActionUpdate:
+			for _, change := range item.Changes {
+				if driftFields[change.Field] {
+					entry.Changes = append(entry.Changes, change)
+				}
+			}
+			if len(entry.Changes) == 0 {
+				continue
+			}
+			entry.Kind = DriftKindChanged
+		default:
+			continue
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return report, nil
+}
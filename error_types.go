@@ -1,5 +1,7 @@
 package rebrandly
 
+import "net/http"
+
 // ErrorCode holds a machine readable status for the error
 type ErrorCode string
 
@@ -165,6 +167,20 @@ type NotFoundResponse struct {
 type ServerErrorResponse struct {
 	// Message to user explaining what happened
 	Message string `json:"message"`
+	// The HTTP status code of the response
+	StatusCode int `json:"-"`
+}
+
+// ResponseError is returned for a response that could not be decoded into
+// any of the REST errors, such as a response with an unsupported status code,
+// or with a body that is not JSON
+type ResponseError struct {
+	// The HTTP status code of the response
+	StatusCode int
+	// The body of the response
+	Body string
+	// The error that occurred while handling the response
+	Err error
 }
 
 func (e BadRequestResponse) Error() string {
@@ -190,3 +206,81 @@ func (e NotFoundResponse) Error() string {
 func (e ServerErrorResponse) Error() string {
 	return e.Message
 }
+
+func (e ResponseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that occurred while handling the response
+func (e ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// IsAlreadyExists returns true when err is an AlreadyExists error of
+// property, or of any property when property is empty.
+//
+// rebrandly answers with either AlreadyExistsResponse or
+// InvalidFormatResponse, with the error possibly nested at its Errors field,
+// so all of them are checked.
+func IsAlreadyExists(err error, property string) bool {
+	matches := func(code ErrorCode, errProperty string) bool {
+		return code == ErrorCodeAlreadyExists &&
+			(property == "" || errProperty == property)
+	}
+	switch e := err.(type) {
+	case AlreadyExistsResponse:
+		return matches(e.Code, e.Property)
+	case InvalidFormatResponse:
+		if matches(e.Code, e.Property) {
+			return true
+		}
+		for _, nested := range e.Errors {
+			if matches(nested.Code, nested.Property) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status code of the response that err was
+// created from, http.StatusOK for nil, or 0 when there was no response, such
+// as on connection errors
+func StatusCode(err error) int {
+	switch e := err.(type) {
+	case nil:
+		return http.StatusOK
+	case BadRequestResponse:
+		return http.StatusBadRequest
+	case UnauthorizedResponse:
+		return http.StatusUnauthorized
+	case InvalidFormatResponse, AlreadyExistsResponse:
+		return http.StatusForbidden
+	case NotFoundResponse:
+		return http.StatusNotFound
+	case ServerErrorResponse:
+		if e.StatusCode != 0 {
+			return e.StatusCode
+		}
+		return http.StatusInternalServerError
+	case ResponseError:
+		return e.StatusCode
+	}
+	return 0
+}
+
+// ErrorCodeOf returns the machine readable code of REST errors, or an empty
+// code for other errors
+func ErrorCodeOf(err error) ErrorCode {
+	switch e := err.(type) {
+	case UnauthorizedResponse:
+		return e.Code
+	case InvalidFormatResponse:
+		return e.Code
+	case AlreadyExistsResponse:
+		return e.Code
+	case NotFoundResponse:
+		return e.Code
+	}
+	return ""
+}
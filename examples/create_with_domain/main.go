@@ -11,11 +11,11 @@ func main() {
 	key := os.Getenv("REBRANDLY_KEY")
 	domainID := os.Getenv("REBRANDLY_DOMAIN_ID")
 
-	request, err := rebrandly.InitCreateLinkEx(rebrandly.LinkRequest{
+	request, err := rebrandly.InitCreateLinkInput(rebrandly.LinkCreateInput{
 		Destination: "https://www.youtube.com/watch?v=x53JHab2ng8",
 		Title:       "Cute Gophers",
 		// Use custom domain, rather then rebrand.ly
-		Domain: rebrandly.DomainRequest{
+		Domain: &rebrandly.LinkDomainInput{
 			ID:  domainID,
 			Ref: fmt.Sprintf("domains/%s", domainID),
 		},
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/yodasco/go-rebrandly"
+)
+
+func main() {
+	key := os.Getenv("REBRANDLY_KEY")
+	asJSON := flag.Bool("json", false, "write the report as JSON")
+	flag.Parse()
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	state, err := rebrandly.ReadDesiredState(file, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	report, err := rebrandly.DetectDrift(context.Background(),
+		rebrandly.APIKey(key), state)
+	if err != nil {
+		panic(err)
+	}
+
+	if *asJSON {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if report.HasDrift() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/yodasco/go-rebrandly"
+)
+
+func main() {
+	key := os.Getenv("REBRANDLY_KEY")
+	journalPath := os.Getenv("JOURNAL_PATH")
+	runID := os.Getenv("RUN_ID")
+	if runID == "" {
+		panic("RUN_ID must be set")
+	}
+
+	file, err := os.Open(journalPath)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	entries, err := rebrandly.ReadJournal(file, runID)
+	if err != nil {
+		panic(err)
+	}
+
+	results := rebrandly.RollbackRun(context.Background(),
+		rebrandly.APIKey(key), entries)
+
+	failed := false
+	for _, result := range results {
+		status := "OK"
+		if result.Err != nil {
+			status = result.Err.Error()
+			failed = true
+		}
+		fmt.Println(result.Entry.Operation, "-", status)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
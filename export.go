@@ -0,0 +1,169 @@
+package rebrandly
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportFormat holds an "enum" of supported export formats
+type ExportFormat string
+
+// Enumeration values for ExportFormat
+const (
+	// One JSON object per line
+	ExportFormatJSONLines ExportFormat = "jsonl"
+	// Comma separated values, with a header line
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+// LinkExportColumns holds the stable set of CSV columns for exported links
+var LinkExportColumns = []string{
+	"id", "title", "slashtag", "destination", "shortUrl",
+	"domain.id", "domain.fullName", "status", "createdAt", "updatedAt",
+	"clicks", "lastClickAt", "favourite", "forwardParameters", "https",
+	"isPublic", "creator.id", "creator.fullName",
+}
+
+// DomainExportColumns holds the stable set of CSV columns for exported domains
+var DomainExportColumns = []string{
+	"id", "ref", "fullName", "topLevelDomain", "createdAt", "updatedAt",
+	"type", "active", "subdomains", "ownerId", "https", "level",
+	"status.dns", "customHomepage",
+}
+
+// recordWriter writes records using one of the export formats
+type recordWriter struct {
+	format ExportFormat
+	json   *json.Encoder
+	csv    *csv.Writer
+}
+
+func newRecordWriter(w io.Writer, format ExportFormat,
+	columns []string) (*recordWriter, error) {
+
+	rw := &recordWriter{format: format}
+	switch format {
+	case ExportFormatJSONLines:
+		rw.json = json.NewEncoder(w)
+		rw.json.SetEscapeHTML(false)
+	case ExportFormatCSV:
+		rw.csv = csv.NewWriter(w)
+		if err := rw.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported export format: %q", format)
+	}
+	return rw, nil
+}
+
+func (rw *recordWriter) write(record interface{}, row []string) error {
+	if rw.json != nil {
+		return rw.json.Encode(record)
+	}
+	return rw.csv.Write(row)
+}
+
+func (rw *recordWriter) flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	return nil
+}
+
+func exportTime(t Timestamp) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func linkExportRow(link LinkRequest) []string {
+	return []string{
+		link.ID,
+		link.Title,
+		link.SlashTag,
+		link.Destination,
+		link.ShortURL,
+		link.Domain.ID,
+		link.Domain.FullName,
+		string(link.Status),
+		exportTime(link.CreatedAt),
+		exportTime(link.UpdatedAt),
+		strconv.FormatInt(link.Clicks, 10),
+		exportTime(link.LastClickAt),
+		strconv.FormatBool(link.Favourite),
+		strconv.FormatBool(link.ForwardParameters),
+		strconv.FormatBool(link.HTTPS),
+		strconv.FormatBool(link.IsPublic),
+		link.Creator.ID,
+		link.Creator.FullName,
+	}
+}
+
+func domainExportRow(domain DomainRequest) []string {
+	return []string{
+		domain.ID,
+		domain.Ref,
+		domain.FullName,
+		domain.TopLevelDomain,
+		exportTime(domain.CreatedAt),
+		exportTime(domain.UpdatedAt),
+		string(domain.Type),
+		strconv.FormatBool(domain.Active),
+		strconv.FormatInt(domain.SubDomains, 10),
+		domain.OwnerID,
+		strconv.FormatBool(domain.HTTPS),
+		strconv.Itoa(domain.Level),
+		domain.Status.DNS,
+		domain.CustomHomepage,
+	}
+}
+
+// ExportLinks walks over all the links of the account, including trashed
+// links, and streams them into w using format.
+// It returns the number of links that were written.
+func ExportLinks(ctx context.Context, sender Sender, w io.Writer,
+	format ExportFormat) (int, error) {
+
+	rw, err := newRecordWriter(w, format, LinkExportColumns)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = WalkAllLinks(ctx, sender, "", func(link LinkRequest) error {
+		count++
+		return rw.write(link, linkExportRow(link))
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, rw.flush()
+}
+
+// ExportDomains walks over all the domains of the account, active or not, and
+// streams them into w using format.
+// It returns the number of domains that were written.
+func ExportDomains(ctx context.Context, sender Sender, w io.Writer,
+	format ExportFormat) (int, error) {
+
+	rw, err := newRecordWriter(w, format, DomainExportColumns)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = WalkAllDomains(ctx, sender, func(domain DomainRequest) error {
+		count++
+		return rw.write(domain, domainExportRow(domain))
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, rw.flush()
+}
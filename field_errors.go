@@ -0,0 +1,115 @@
+package rebrandly
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// FieldError holds a single validation error of a field
+type FieldError struct {
+	// Machine readable code of the error
+	Code ErrorCode
+	// Message to the user explaining what is wrong
+	Message string
+	// The request property as returned by rebrandly, e.g. domain.id
+	Property string
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// jsonFieldNames maps the lower cased JSON paths of the fields of t, such as
+// domain.id, into the Go paths of the same fields, such as Domain.ID
+func jsonFieldNames(t reflect.Type, jsonPrefix, goPrefix string,
+	names map[string]string) {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		jsonPath := jsonPrefix + strings.ToLower(name)
+		goPath := goPrefix + field.Name
+		names[jsonPath] = goPath
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct &&
+			!reflect.PtrTo(fieldType).Implements(jsonMarshalerType) {
+			jsonFieldNames(fieldType, jsonPath+".", goPath+".", names)
+		}
+	}
+}
+
+// FieldErrors maps the validation errors of err into the Go fields of model,
+// such as LinkRequest{} or DomainRequest{}.
+//
+// The keys of the result are Go field paths, e.g. a slashtag error is keyed by
+// SlashTag, and a domain.id error by Domain.ID. Properties that are not part of
+// model are keyed by the property as returned by rebrandly.
+//
+// err can be any REST error that holds a Property, including the nested
+// errors of InvalidFormatResponse. nil is returned for other errors.
+func FieldErrors(err error, model interface{}) map[string][]FieldError {
+	var fieldErrors []FieldError
+	switch e := err.(type) {
+	case InvalidFormatResponse:
+		if e.Property != "" || len(e.Errors) == 0 {
+			fieldErrors = append(fieldErrors, FieldError{
+				Code:     e.Code,
+				Message:  e.Message,
+				Property: e.Property,
+			})
+		}
+		for _, nested := range e.Errors {
+			fieldErrors = append(fieldErrors, FieldError{
+				Code:     nested.Code,
+				Message:  nested.Message,
+				Property: nested.Property,
+			})
+		}
+	case AlreadyExistsResponse:
+		fieldErrors = append(fieldErrors, FieldError{
+			Code:     e.Code,
+			Message:  e.Message,
+			Property: e.Property,
+		})
+	case NotFoundResponse:
+		fieldErrors = append(fieldErrors, FieldError{
+			Code:     e.Code,
+			Message:  e.Message,
+			Property: e.Property,
+		})
+	default:
+		return nil
+	}
+
+	names := make(map[string]string)
+	if model != nil {
+		jsonFieldNames(reflect.TypeOf(model), "", "", names)
+	}
+	result := make(map[string][]FieldError)
+	for _, fieldError := range fieldErrors {
+		key := fieldError.Property
+		if name, ok := names[strings.ToLower(key)]; ok {
+			key = name
+		}
+		result[key] = append(result[key], fieldError)
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package rebrandly
+
+import (
+	"context"
+	"fmt"
+)
+
+// LinkConflictError is returned by CreateLinkIdempotent when the slashtag is
+// already taken by a link that points to a different destination
+type LinkConflictError struct {
+	// The link that was requested to be created
+	Requested LinkRequest
+	// The link that already holds the slashtag
+	Existing LinkRequest
+}
+
+func (e LinkConflictError) Error() string {
+	return fmt.Sprintf("Slashtag %q already exists with destination %q",
+		e.Existing.SlashTag, e.Existing.Destination)
+}
+
+// requestedLink returns the fields of input as a LinkRequest
+func requestedLink(input LinkCreateInput) LinkRequest {
+	link := LinkRequest{
+		Title:       input.Title,
+		SlashTag:    input.SlashTag,
+		Destination: input.Destination,
+	}
+	if input.Domain != nil {
+		link.Domain = DomainRequest{
+			ID:       input.Domain.ID,
+			Ref:      input.Domain.Ref,
+			FullName: input.Domain.FullName,
+		}
+	}
+	if input.Favourite != nil {
+		link.Favourite = *input.Favourite
+	}
+	if input.ForwardParameters != nil {
+		link.ForwardParameters = *input.ForwardParameters
+	}
+	return link
+}
+
+// CreateLinkIdempotent creates a link from input. When the slashtag already
+// exists at the domain, the existing link is fetched and returned if it points
+// to the same destination, so the call can be safely repeated.
+//
+// If the existing link points to a different destination, LinkConflictError
+// is returned.
+func CreateLinkIdempotent(ctx context.Context, sender Sender,
+	input LinkCreateInput) (LinkRequest, error) {
+
+	request, err := InitCreateLinkInput(input)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	link, err := sendLinkRequest(ctx, sender, request)
+	if err == nil || input.SlashTag == "" || !IsAlreadyExists(err, "slashtag") {
+		return link, err
+	}
+
+	domainID := ""
+	if input.Domain != nil {
+		domainID = input.Domain.ID
+	}
+	existing, findErr := FindLinkInDomain(ctx, sender, domainID, input.SlashTag)
+	if findErr != nil {
+		return LinkRequest{}, findErr
+	}
+	options := NormalizeOptions{}
+	if NormalizeDestination(existing.Destination, options) !=
+		NormalizeDestination(input.Destination, options) {
+		return LinkRequest{}, LinkConflictError{
+			Requested: requestedLink(input),
+			Existing:  existing,
+		}
+	}
+	return existing, nil
+}
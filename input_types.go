@@ -0,0 +1,109 @@
+package rebrandly
+
+// The following section holds the structures that are sent to rebrandly when
+// creating and updating links.
+// Unlike LinkRequest, they hold only the fields that can be set by the client,
+// so server managed fields such as id, clicks and timestamps are never sent.
+
+// Bool returns a pointer to b, for setting the optional fields of the input
+// structs
+func Bool(b bool) *bool {
+	return &b
+}
+
+// LinkDomainInput is a reference to the branded domain of a link
+//
+// JSON example for such input
+//
+//	{
+//	  "id": "8f104cc5b6ee4a4ba7897b06ac2ddcfb",
+//	  "ref": "/domains/8f104cc5b6ee4a4ba7897b06ac2ddcfb"
+//	}
+type LinkDomainInput struct {
+	// Unique identifier of the branded domain
+	ID string `json:"id"`
+	// API path to the branded domain
+	Ref string `json:"ref,omitempty"`
+	// Full name of the branded domain
+	FullName string `json:"fullName,omitempty"`
+}
+
+// NewLinkDomainInput returns a reference to domain, or nil when domain has no
+// ID
+func NewLinkDomainInput(domain DomainRequest) *LinkDomainInput {
+	if domain.ID == "" {
+		return nil
+	}
+	return &LinkDomainInput{
+		ID:       domain.ID,
+		Ref:      domain.Ref,
+		FullName: domain.FullName,
+	}
+}
+
+// LinkCreateInput holds the fields for creating a new link.
+// Empty fields are not sent, and are left for rebrandly to decide.
+//
+// JSON example for such input
+//
+//	{
+//	  "destination": "https://www.youtube.com/watch?v=x53JHab2ng8",
+//	  "slashtag": "gophers",
+//	  "domain": {
+//	    "id": "8f104cc5b6ee4a4ba7897b06ac2ddcfb"
+//	  },
+//	  "favourite": true
+//	}
+type LinkCreateInput struct {
+	// The destination URL you want your branded short link to point to
+	Destination string `json:"destination"`
+	// The keyword section of your branded short link, empty for a random one
+	SlashTag string `json:"slashtag,omitempty"`
+	// A title you assign to the branded short link, empty for the title of
+	// the destination page
+	Title string `json:"title,omitempty"`
+	// The branded domain of the link, nil for rebrand.ly
+	Domain *LinkDomainInput `json:"domain,omitempty"`
+	// Whether a link is favourited (loved) or not, nil for the server default
+	Favourite *bool `json:"favourite,omitempty"`
+	// Whether query parameters in short URL will be forwarded to destination
+	// URL, nil for the server default
+	ForwardParameters *bool `json:"forwardParameters,omitempty"`
+}
+
+// LinkUpdateInput holds the fields for updating an existed link.
+//
+// Destination, SlashTag and Title are required by rebrandly.
+//
+// Note: If Domain is nil, then rebrandly changes the domain to rebrand.ly.
+type LinkUpdateInput struct {
+	// The destination URL you want your branded short link to point to
+	Destination string `json:"destination"`
+	// The keyword section of your branded short link
+	SlashTag string `json:"slashtag"`
+	// A title you assign to the branded short link
+	Title string `json:"title"`
+	// The branded domain of the link
+	Domain *LinkDomainInput `json:"domain,omitempty"`
+	// Whether a link is favourited (loved) or not, nil to keep it unchanged
+	Favourite *bool `json:"favourite,omitempty"`
+	// Whether query parameters in short URL will be forwarded to destination
+	// URL, nil to keep it unchanged
+	ForwardParameters *bool `json:"forwardParameters,omitempty"`
+	// Status of the link, empty to keep it unchanged
+	Status LinkStatus `json:"status,omitempty"`
+}
+
+// NewLinkUpdateInput returns an update input that holds all the client fields
+// of link, including its domain
+func NewLinkUpdateInput(link LinkRequest) LinkUpdateInput {
+	return LinkUpdateInput{
+		Destination:       link.Destination,
+		SlashTag:          link.SlashTag,
+		Title:             link.Title,
+		Domain:            NewLinkDomainInput(link.Domain),
+		Favourite:         Bool(link.Favourite),
+		ForwardParameters: Bool(link.ForwardParameters),
+		Status:            link.Status,
+	}
+}
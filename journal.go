@@ -0,0 +1,270 @@
+package rebrandly
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JournalOperation holds an "enum" of the journaled operations
+type JournalOperation string
+
+// Enumeration values for JournalOperation
+const (
+	JournalOperationCreate JournalOperation = "create"
+	JournalOperationUpdate JournalOperation = "update"
+	JournalOperationDelete JournalOperation = "delete"
+)
+
+// JournalEntry holds a single operation that was made on a link
+type JournalEntry struct {
+	// Identifier of the run the operation was part of
+	RunID string `json:"runId"`
+	// UTC date/time of the operation
+	Time Timestamp `json:"time"`
+	// The operation that was made
+	Operation JournalOperation `json:"operation"`
+	// Whether a deleted link was moved into the trash
+	Trash bool `json:"trash,omitempty"`
+	// The link before the operation, nil for JournalOperationCreate
+	Before *LinkRequest `json:"before,omitempty"`
+	// The link after the operation, nil for JournalOperationDelete
+	After *LinkRequest `json:"after,omitempty"`
+}
+
+// Journal stores the entries of journaled operations
+type Journal interface {
+	Append(entry JournalEntry) error
+}
+
+// FileJournal is a Journal that appends entries as JSON Lines into a file.
+// It is safe for concurrent use.
+type FileJournal struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileJournal returns a FileJournal that writes into filePath
+func NewFileJournal(filePath string) *FileJournal {
+	return &FileJournal{Path: filePath}
+}
+
+// Append implements the Journal interface
+func (j *FileJournal) Append(entry JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	file, err := os.OpenFile(j.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadJournal reads the entries of runID from a journal that was written by
+// FileJournal. All entries are returned when runID is empty.
+func ReadJournal(r io.Reader, runID string) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	decoder := json.NewDecoder(r)
+	for {
+		var entry JournalEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		if runID == "" || entry.RunID == runID {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// idCounter makes the fallback identifiers of randomHex unique
+var idCounter uint64
+
+// randomHex returns n (up to 32) random bytes encoded as hex. When the system
+// random source fails, the bytes are derived from the time, the process ID
+// and a counter instead, so they are still unique.
+func randomHex(n int) string {
+	random := make([]byte, n)
+	if _, err := rand.Read(random); err != nil {
+		seed := fmt.Sprintf("%d-%d-%d", time.Now().UnixNano(), os.Getpid(),
+			atomic.AddUint64(&idCounter, 1))
+		sum := sha256.Sum256([]byte(seed))
+		copy(random, sum[:])
+	}
+	return hex.EncodeToString(random)
+}
+
+// NewRunID returns a new unique identifier for a run of bulk operations
+func NewRunID() string {
+	return time.Now().UTC().Format("20060102T150405") + "-" + randomHex(4)
+}
+
+// JournalError is returned by JournalSender when the operation was made, but
+// could not be written into the journal
+type JournalError struct {
+	Err error
+}
+
+func (e JournalError) Error() string {
+	return fmt.Sprintf("Operation was made, but not journaled: %v", e.Err)
+}
+
+// JournalSender is a Sender that writes every create, update and delete of a
+// link into a Journal, with snapshots of the link before and after the
+// operation. Other requests are sent as is.
+//
+// Any of the bulk operations can be journaled by using a JournalSender as
+// their Sender, and be reverted later using RollbackRun.
+type JournalSender struct {
+	Sender  Sender
+	Journal Journal
+	RunID   string
+}
+
+// NewJournalSender returns a JournalSender for a run of runID, or a new run
+// when runID is empty
+func NewJournalSender(sender Sender, journal Journal, runID string) *JournalSender {
+	if runID == "" {
+		runID = NewRunID()
+	}
+	return &JournalSender{Sender: sender, Journal: journal, RunID: runID}
+}
+
+// JournalMiddleware returns a Middleware that journals the requests of a run,
+// as JournalSender does
+func JournalMiddleware(journal Journal, runID string) Middleware {
+	return func(next Sender) Sender {
+		return NewJournalSender(next, journal, runID)
+	}
+}
+
+// Send implements the Sender interface.
+//
+// When the operation succeeds but cannot be journaled, the answer is returned
+// together with JournalError.
+func (s *JournalSender) Send(ctx context.Context, r Request) (interface{}, error) {
+	entry := JournalEntry{RunID: s.RunID}
+	switch r.ActionType {
+	case ActionTypeLinkCreate:
+		entry.Operation = JournalOperationCreate
+	case ActionTypeLinkUpdate:
+		entry.Operation = JournalOperationUpdate
+	case ActionTypeLinkDelete:
+		entry.Operation = JournalOperationDelete
+		entry.Trash, _ = strconv.ParseBool(r.URL.Query().Get("trash"))
+	default:
+		return s.Sender.Send(ctx, r)
+	}
+
+	if entry.Operation != JournalOperationCreate {
+		before, err := GetLink(ctx, s.Sender, path.Base(r.URL.Path))
+		if err != nil {
+			return nil, err
+		}
+		entry.Before = &before
+	}
+
+	answer, err := s.Sender.Send(ctx, r)
+	if err != nil {
+		return answer, err
+	}
+	if link, ok := answer.(LinkRequest); ok &&
+		entry.Operation != JournalOperationDelete {
+		entry.After = &link
+	}
+	entry.Time = NewTimestamp(time.Now().UTC())
+	if err := s.Journal.Append(entry); err != nil {
+		return answer, JournalError{Err: err}
+	}
+	return answer, nil
+}
+
+// RollbackResult holds the outcome of reverting a single JournalEntry
+type RollbackResult struct {
+	Entry JournalEntry
+	// The link as returned by rebrandly after it was reverted
+	Link LinkRequest
+	// The error that caused the revert to fail
+	Err error
+}
+
+// RollbackRun reverts the operations of entries, from the last to the first:
+//   - Created links are deleted
+//   - Updated links get back all of their previous fields
+//   - Trashed links are restored, and permanently deleted links are created
+//     again with their previous fields (and a new ID)
+func RollbackRun(ctx context.Context, sender Sender, entries []JournalEntry) []RollbackResult {
+	results := make([]RollbackResult, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		result := RollbackResult{Entry: entry}
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.Link, result.Err = rollbackEntry(ctx, sender, entry)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func rollbackEntry(ctx context.Context, sender Sender, entry JournalEntry) (LinkRequest, error) {
+	switch {
+	case entry.Operation == JournalOperationCreate && entry.After != nil:
+		request, err := InitDeleteLink(entry.After.ID, false)
+		if err != nil {
+			return LinkRequest{}, err
+		}
+		_, err = sender.Send(ctx, request)
+		return LinkRequest{}, err
+
+	case entry.Operation == JournalOperationUpdate && entry.Before != nil:
+		request, err := InitUpdateLinkFrom(*entry.Before)
+		if err != nil {
+			return LinkRequest{}, err
+		}
+		return sendLinkRequest(ctx, sender, request)
+
+	case entry.Operation == JournalOperationDelete && entry.Before != nil:
+		if entry.Trash {
+			return RestoreTrashedLink(ctx, sender, entry.Before.ID)
+		}
+		before := entry.Before
+		request, err := InitCreateLinkInput(LinkCreateInput{
+			Destination:       before.Destination,
+			SlashTag:          before.SlashTag,
+			Title:             before.Title,
+			Domain:            NewLinkDomainInput(before.Domain),
+			Favourite:         Bool(before.Favourite),
+			ForwardParameters: Bool(before.ForwardParameters),
+		})
+		if err != nil {
+			return LinkRequest{}, err
+		}
+		return sendLinkRequest(ctx, sender, request)
+	}
+	return LinkRequest{}, fmt.Errorf("Entry cannot be rolled back: %s",
+		entry.Operation)
+}
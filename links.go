@@ -0,0 +1,152 @@
+package rebrandly
+
+import (
+	"context"
+	"fmt"
+)
+
+// LinkMutation changes one or more fields of a link prior to its update
+type LinkMutation func(link *LinkRequest)
+
+// SetDestination is a LinkMutation that changes the destination of a link
+func SetDestination(destination string) LinkMutation {
+	return func(link *LinkRequest) {
+		link.Destination = destination
+	}
+}
+
+// SetTitle is a LinkMutation that changes the title of a link
+func SetTitle(title string) LinkMutation {
+	return func(link *LinkRequest) {
+		link.Title = title
+	}
+}
+
+// SetSlashTag is a LinkMutation that changes the slashtag of a link
+func SetSlashTag(slashTag string) LinkMutation {
+	return func(link *LinkRequest) {
+		link.SlashTag = slashTag
+	}
+}
+
+// SetFavourite is a LinkMutation that changes whether a link is favourite
+func SetFavourite(favourite bool) LinkMutation {
+	return func(link *LinkRequest) {
+		link.Favourite = favourite
+	}
+}
+
+// SetForwardParameters is a LinkMutation that changes whether a link forwards
+// its query parameters
+func SetForwardParameters(forwardParameters bool) LinkMutation {
+	return func(link *LinkRequest) {
+		link.ForwardParameters = forwardParameters
+	}
+}
+
+// SetStatus is a LinkMutation that changes the status of a link
+func SetStatus(status LinkStatus) LinkMutation {
+	return func(link *LinkRequest) {
+		link.Status = status
+	}
+}
+
+// Names of the fields that can be used as a mask for UpdateLinkMask
+const (
+	LinkFieldTitle             = "title"
+	LinkFieldSlashTag          = "slashtag"
+	LinkFieldDestination       = "destination"
+	LinkFieldFavourite         = "favourite"
+	LinkFieldForwardParameters = "forwardParameters"
+)
+
+// MaskMutation returns a LinkMutation that copies the fields named by mask
+// from fields
+func MaskMutation(fields LinkRequest, mask ...string) (LinkMutation, error) {
+	var mutations []LinkMutation
+	for _, name := range mask {
+		switch name {
+		case LinkFieldTitle:
+			mutations = append(mutations, SetTitle(fields.Title))
+		case LinkFieldSlashTag:
+			mutations = append(mutations, SetSlashTag(fields.SlashTag))
+		case LinkFieldDestination:
+			mutations = append(mutations, SetDestination(fields.Destination))
+		case LinkFieldFavourite:
+			mutations = append(mutations, SetFavourite(fields.Favourite))
+		case LinkFieldForwardParameters:
+			mutations = append(mutations,
+				SetForwardParameters(fields.ForwardParameters))
+		default:
+			return nil, fmt.Errorf("Unsupported field at mask: %q", name)
+		}
+	}
+	return func(link *LinkRequest) {
+		for _, mutation := range mutations {
+			mutation(link)
+		}
+	}, nil
+}
+
+// GetLink fetches the details of linkID using InitLinkDetails
+func GetLink(ctx context.Context, sender Sender, linkID string) (LinkRequest, error) {
+	request, err := InitLinkDetails(linkID)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	return sendLinkRequest(ctx, sender, request)
+}
+
+// sendLinkRequest sends a request that is answered by a single LinkRequest
+func sendLinkRequest(ctx context.Context, sender Sender, request Request) (LinkRequest, error) {
+	answer, err := sender.Send(ctx, request)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	link, ok := answer.(LinkRequest)
+	if !ok {
+		return LinkRequest{}, fmt.Errorf("Unexpected answer type: %T", answer)
+	}
+	return link, nil
+}
+
+// InitUpdateLinkFrom initialize the Request struct for updating link with all
+// of its fields, including its domain, so nothing besides the changed fields
+// is modified by rebrandly.
+func InitUpdateLinkFrom(link LinkRequest) (Request, error) {
+	return InitUpdateLinkInput(link.ID, NewLinkUpdateInput(link))
+}
+
+// UpdateLink fetches the current state of linkID, applies mutations on it,
+// and updates the link with the complete result.
+//
+// Unlike InitUpdateLink, fields that are not touched by mutations, including
+// the domain, keep their current value.
+func UpdateLink(ctx context.Context, sender Sender, linkID string,
+	mutations ...LinkMutation) (LinkRequest, error) {
+
+	link, err := GetLink(ctx, sender, linkID)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	for _, mutation := range mutations {
+		mutation(&link)
+	}
+	request, err := InitUpdateLinkFrom(link)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	return sendLinkRequest(ctx, sender, request)
+}
+
+// UpdateLinkMask is like UpdateLink, but only the fields named by mask are
+// copied from fields
+func UpdateLinkMask(ctx context.Context, sender Sender, linkID string,
+	fields LinkRequest, mask ...string) (LinkRequest, error) {
+
+	mutation, err := MaskMutation(fields, mask...)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	return UpdateLink(ctx, sender, linkID, mutation)
+}
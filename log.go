@@ -0,0 +1,80 @@
+package rebrandly
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redacted replaces the values of sensitive headers and query parameters
+const redacted = "REDACTED"
+
+// DefaultSensitiveParams holds the query parameters that are always redacted
+var DefaultSensitiveParams = []string{"apikey", "api_key", "access_token", "token"}
+
+// sensitiveHeaders holds the lower cased HTTP headers that are always
+// redacted
+var sensitiveHeaders = map[string]bool{
+	"apikey":        true,
+	"authorization": true,
+}
+
+func isSensitive(name string, sensitive []string) bool {
+	for _, param := range sensitive {
+		if strings.EqualFold(name, param) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL returns u with the values of DefaultSensitiveParams and of
+// sensitiveParams replaced
+func RedactURL(u url.URL, sensitiveParams ...string) url.URL {
+	if u.RawQuery == "" {
+		return u
+	}
+	q := u.Query()
+	for name, values := range q {
+		if isSensitive(name, DefaultSensitiveParams) ||
+			isSensitive(name, sensitiveParams) {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u
+}
+
+// RedactHeader returns a copy of header, with the values of the apikey and
+// authorization headers replaced
+func RedactHeader(header http.Header) http.Header {
+	result := make(http.Header, len(header))
+	for name, values := range header {
+		values = append([]string(nil), values...)
+		if sensitiveHeaders[strings.ToLower(name)] {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+		result[name] = values
+	}
+	return result
+}
+
+// RedactError returns err with the values of DefaultSensitiveParams and of
+// sensitiveParams replaced at its URL, when err is a *url.Error, such as the
+// errors of http.Client that hold the whole URL of the request
+func RedactError(err error, sensitiveParams ...string) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redactedURL := redacted
+	if parsed, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		u := RedactURL(*parsed, sensitiveParams...)
+		redactedURL = u.String()
+	}
+	return &url.Error{Op: urlErr.Op, URL: redactedURL, Err: urlErr.Err}
+}
@@ -0,0 +1,62 @@
+//go:build go1.21
+// +build go1.21
+
+package rebrandly
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LoggingMiddleware returns a Middleware that logs every request into logger,
+// with its action type, method, path, status, latency and error code.
+//
+// The apikey header is never logged, and the values of sensitiveParams, as
+// well as of DefaultSensitiveParams, are redacted from the query, and from
+// the URL of connection errors.
+// Successful requests are logged at Info level, REST errors at Warn level,
+// and the rest of the errors at Error level.
+//
+// LoggingMiddleware requires Go 1.21 or later, for the log/slog package.
+func LoggingMiddleware(logger *slog.Logger, sensitiveParams ...string) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			start := time.Now()
+			answer, err := next.Send(ctx, r)
+			latency := time.Since(start)
+
+			u := RedactURL(r.URL, sensitiveParams...)
+			status := StatusCode(err)
+			attrs := []slog.Attr{
+				slog.String("action", string(r.ActionType)),
+				slog.String("method", r.Method),
+				slog.String("path", u.Path),
+				slog.Int("status", status),
+				slog.Duration("latency", latency),
+			}
+			if u.RawQuery != "" {
+				attrs = append(attrs, slog.String("query", u.RawQuery))
+			}
+			if len(r.Header) > 0 {
+				attrs = append(attrs,
+					slog.Any("header", RedactHeader(r.Header)))
+			}
+
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelError
+				if status >= 400 && status < 500 {
+					level = slog.LevelWarn
+				}
+				if code := ErrorCodeOf(err); code != "" {
+					attrs = append(attrs, slog.String("error_code", string(code)))
+				}
+				attrs = append(attrs, slog.String("error",
+					RedactError(err, sensitiveParams...).Error()))
+			}
+			logger.LogAttrs(ctx, level, "rebrandly request", attrs...)
+			return answer, err
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLoggingMiddlewareRedactsConnectionErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	failing := SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+		return nil, &url.Error{
+			Op:  "Get",
+			URL: r.URL.String(),
+			Err: errors.New("connection refused"),
+		}
+	})
+
+	request, err := InitLinkDetails("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.URL.RawQuery = "token=secret&custom=private&page=2"
+	sender := Chain(failing, LoggingMiddleware(logger, "custom"))
+	_, err = sender.Send(context.Background(), request)
+	if _, ok := err.(*url.Error); !ok {
+		t.Fatalf("Expected the original error, got %v", err)
+	}
+
+	output := buf.String()
+	for _, secret := range []string{"secret", "private"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Log holds %q: %s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "connection refused") ||
+		!strings.Contains(output, "page=2") {
+		t.Errorf("Log is missing the error: %s", output)
+	}
+}
@@ -0,0 +1,115 @@
+package rebrandly
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseShortURL splits a short URL, such as brand.cool/promo or
+// https://brand.cool/promo, into its domain and slashtag
+func ParseShortURL(shortURL string) (domain, slashTag string, err error) {
+	shortURL = strings.TrimSpace(shortURL)
+	if !strings.Contains(shortURL, "://") {
+		shortURL = "https://" + shortURL
+	}
+	u, err := url.Parse(shortURL)
+	if err != nil {
+		return "", "", err
+	}
+	domain = strings.ToLower(u.Hostname())
+	slashTag = strings.Trim(u.Path, "/")
+	if domain == "" || slashTag == "" || strings.Contains(slashTag, "/") {
+		return "", "", fmt.Errorf("Invalid short URL: %q", shortURL)
+	}
+	return domain, slashTag, nil
+}
+
+// FindDomainByName walks over the domains of the account, and returns the one
+// with the full name of name.
+// NotFoundResponse is returned when there is no such domain.
+func FindDomainByName(ctx context.Context, sender Sender, name string) (DomainRequest, error) {
+	var found DomainRequest
+	err := WalkAllDomains(ctx, sender, func(domain DomainRequest) error {
+		if strings.EqualFold(domain.FullName, name) {
+			found = domain
+			return ErrStopWalk
+		}
+		return nil
+	})
+	if err != nil {
+		return DomainRequest{}, err
+	}
+	if found.ID == "" {
+		return DomainRequest{}, NotFoundResponse{
+			Message:  "Not found",
+			Code:     ErrorCodeNotFound,
+			Property: "domain",
+		}
+	}
+	return found, nil
+}
+
+// FindLinkBySlashTag returns the link of slashTag at the domain with the full
+// name of domainName, or rebrand.ly when empty.
+// NotFoundResponse is returned when there is no such domain or link.
+func FindLinkBySlashTag(ctx context.Context, sender Sender, domainName,
+	slashTag string) (LinkRequest, error) {
+
+	if domainName == "" {
+		domainName = DefaultDomainName
+	}
+	var domainID string
+	domain, err := FindDomainByName(ctx, sender, domainName)
+	if err == nil {
+		domainID = domain.ID
+	} else if _, ok := err.(NotFoundResponse); !ok ||
+		!strings.EqualFold(domainName, DefaultDomainName) {
+		return LinkRequest{}, err
+	}
+
+	return FindLinkInDomain(ctx, sender, domainID, slashTag)
+}
+
+// FindLinkInDomain returns the link of slashTag at the domain of domainID, or
+// rebrand.ly when empty, using list requests that are filtered by slashTag.
+// An active link is preferred over a trashed one.
+// NotFoundResponse is returned when there is no such link.
+func FindLinkInDomain(ctx context.Context, sender Sender, domainID,
+	slashTag string) (LinkRequest, error) {
+
+	var found LinkRequest
+	filter := LinkFilter{DomainID: domainID, SlashTag: slashTag}
+	statuses := []LinkStatus{LinkStatusActive, LinkStatusTrashed}
+	err := walkLinkStatuses(ctx, sender, filter, statuses, func(link LinkRequest) error {
+		if link.SlashTag == slashTag &&
+			(domainID != "" || link.Domain.FullName == DefaultDomainName) {
+			found = link
+			return ErrStopWalk
+		}
+		return nil
+	})
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	if found.ID == "" {
+		return LinkRequest{}, NotFoundResponse{
+			Message:  "Not found",
+			Code:     ErrorCodeNotFound,
+			Property: "slashtag",
+		}
+	}
+	return found, nil
+}
+
+// FindLinkByShortURL returns the link of a short URL, such as
+// brand.cool/promo.
+// NotFoundResponse is returned when there is no such link.
+func FindLinkByShortURL(ctx context.Context, sender Sender, shortURL string) (LinkRequest, error) {
+	domain, slashTag, err := ParseShortURL(shortURL)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	return FindLinkBySlashTag(ctx, sender, domain, slashTag)
+}
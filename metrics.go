@@ -0,0 +1,254 @@
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram buckets of Metrics
+var DefaultLatencyBuckets = []float64{
+	0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// errorKey holds the labels of the error counter
+type errorKey struct {
+	action ActionTypes
+	code   ErrorCode
+	status int
+}
+
+// histogram holds the latency observations of a single action
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Metrics collects counters and latency histograms of requests, labelled by
+// their ActionTypes. It is safe for concurrent use.
+//
+// The collected metrics are exposed using the Prometheus text exposition
+// format by WritePrometheus and ServeHTTP, or as an expvar.Var by Expvar, so
+// no metrics library is required.
+type Metrics struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	requests map[ActionTypes]uint64
+	errors   map[errorKey]uint64
+	latency  map[ActionTypes]*histogram
+}
+
+// NewMetrics returns an empty Metrics that uses buckets as the upper bounds
+// of its latency histograms, or DefaultLatencyBuckets when none are given
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		buckets:  buckets,
+		requests: make(map[ActionTypes]uint64),
+		errors:   make(map[errorKey]uint64),
+		latency:  make(map[ActionTypes]*histogram),
+	}
+}
+
+// Observe records a single request of action that took latency and ended
+// with err
+func (m *Metrics) Observe(action ActionTypes, latency time.Duration, err error) {
+	seconds := latency.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[action]++
+	if err != nil {
+		m.errors[errorKey{
+			action: action,
+			code:   ErrorCodeOf(err),
+			status: StatusCode(err),
+		}]++
+	}
+
+	h, ok := m.latency[action]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[action] = h
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Middleware returns a Middleware that records every request into m
+func (m *Metrics) Middleware() Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			start := time.Now()
+			answer, err := next.Send(ctx, r)
+			m.Observe(r.ActionType, time.Since(start), err)
+			return answer, err
+		})
+	}
+}
+
+// escapeLabel escapes a label value for the Prometheus text format
+func escapeLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return strings.Replace(value, "\n", `\n`, -1)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedActions(m map[ActionTypes]uint64) []ActionTypes {
+	actions := make([]ActionTypes, 0, len(m))
+	for action := range m {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
+
+func (m *Metrics) sortedErrors() []errorKey {
+	keys := make([]errorKey, 0, len(m.errors))
+	for key := range m.errors {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].action != keys[j].action {
+			return keys[i].action < keys[j].action
+		}
+		if keys[i].code != keys[j].code {
+			return keys[i].code < keys[j].code
+		}
+		return keys[i].status < keys[j].status
+	})
+	return keys
+}
+
+// WritePrometheus writes the metrics into w using the Prometheus text
+// exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	m.mu.Lock()
+	actions := sortedActions(m.requests)
+
+	buf.WriteString("# HELP rebrandly_requests_total Number of requests sent to rebrandly.\n")
+	buf.WriteString("# TYPE rebrandly_requests_total counter\n")
+	for _, action := range actions {
+		fmt.Fprintf(&buf, "rebrandly_requests_total{action=\"%s\"} %d\n",
+			escapeLabel(string(action)), m.requests[action])
+	}
+
+	buf.WriteString("# HELP rebrandly_request_errors_total Number of requests that failed.\n")
+	buf.WriteString("# TYPE rebrandly_request_errors_total counter\n")
+	for _, key := range m.sortedErrors() {
+		fmt.Fprintf(&buf,
+			"rebrandly_request_errors_total{action=\"%s\",code=\"%s\",status=\"%d\"} %d\n",
+			escapeLabel(string(key.action)), escapeLabel(string(key.code)),
+			key.status, m.errors[key])
+	}
+
+	buf.WriteString("# HELP rebrandly_request_duration_seconds Latency of requests sent to rebrandly.\n")
+	buf.WriteString("# TYPE rebrandly_request_duration_seconds histogram\n")
+	for _, action := range actions {
+		h := m.latency[action]
+		label := escapeLabel(string(action))
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&buf,
+				"rebrandly_request_duration_seconds_bucket{action=\"%s\",le=\"%s\"} %d\n",
+				label, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(&buf,
+			"rebrandly_request_duration_seconds_bucket{action=\"%s\",le=\"+Inf\"} %d\n",
+			label, h.count)
+		fmt.Fprintf(&buf, "rebrandly_request_duration_seconds_sum{action=\"%s\"} %s\n",
+			label, formatFloat(h.sum))
+		fmt.Fprintf(&buf, "rebrandly_request_duration_seconds_count{action=\"%s\"} %d\n",
+			label, h.count)
+	}
+	m.mu.Unlock()
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ServeHTTP writes the metrics using the Prometheus text exposition format,
+// so m can be registered as the handler of a metrics endpoint
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+// MetricsSnapshot holds a copy of the metrics, as exposed by Expvar
+type MetricsSnapshot struct {
+	// Number of requests by action
+	Requests map[string]uint64 `json:"requests"`
+	// Number of failed requests by action, error code and status, joined
+	// by "/"
+	Errors map[string]uint64 `json:"errors"`
+	// Total latency in seconds by action
+	LatencySum map[string]float64 `json:"latencySum"`
+	// Number of latency observations by action
+	LatencyCount map[string]uint64 `json:"latencyCount"`
+	// Cumulative latency histogram by action, holding the number of
+	// observations by the upper bound of each bucket, including "+Inf"
+	LatencyBuckets map[string]map[string]uint64 `json:"latencyBuckets"`
+}
+
+// Snapshot returns a copy of the current metrics
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := MetricsSnapshot{
+		Requests:       make(map[string]uint64, len(m.requests)),
+		Errors:         make(map[string]uint64, len(m.errors)),
+		LatencySum:     make(map[string]float64, len(m.latency)),
+		LatencyCount:   make(map[string]uint64, len(m.latency)),
+		LatencyBuckets: make(map[string]map[string]uint64, len(m.latency)),
+	}
+	for action, count := range m.requests {
+		snapshot.Requests[string(action)] = count
+	}
+	for key, count := range m.errors {
+		name := fmt.Sprintf("%s/%s/%d", key.action, key.code, key.status)
+		snapshot.Errors[name] = count
+	}
+	for action, h := range m.latency {
+		snapshot.LatencySum[string(action)] = h.sum
+		snapshot.LatencyCount[string(action)] = h.count
+		buckets := make(map[string]uint64, len(m.buckets)+1)
+		for i, bound := range m.buckets {
+			buckets[formatFloat(bound)] = h.counts[i]
+		}
+		buckets["+Inf"] = h.count
+		snapshot.LatencyBuckets[string(action)] = buckets
+	}
+	return snapshot
+}
+
+// Expvar returns an expvar.Var of the metrics, that can be published using
+// expvar.Publish
+func (m *Metrics) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return m.Snapshot()
+	})
+}
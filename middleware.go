@@ -0,0 +1,46 @@
+package rebrandly
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps the execution of a Request by next.
+//
+// A middleware sees the Request before it is sent, including its ActionType,
+// Method, URL and Operation, and can change it, answer it by itself, or pass
+// it on to next and look at the answer and error that are returned.
+type Middleware func(next Sender) Sender
+
+// Chain returns a Sender that executes requests by sender, wrapped by
+// middlewares.
+// The first middleware is the outermost one, so it sees the request first and
+// the answer last.
+func Chain(sender Sender, middlewares ...Middleware) Sender {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		sender = middlewares[i](sender)
+	}
+	return sender
+}
+
+// WithHeader returns a Middleware that sets the HTTP header name to value on
+// every request
+func WithHeader(name, value string) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			return next.Send(ctx, withHeader(r, name, value))
+		})
+	}
+}
+
+// withHeader returns r with the HTTP header name set to value. The header of
+// r is copied, so it is not shared with other requests.
+func withHeader(r Request, name, value string) Request {
+	header := make(http.Header, len(r.Header)+1)
+	for key, values := range r.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	header.Set(name, value)
+	r.Header = header
+	return r
+}
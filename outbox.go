@@ -0,0 +1,515 @@
+package rebrandly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of Outbox
+const (
+	DefaultOutboxMaxAttempts  = 10
+	DefaultOutboxMinBackoff   = time.Second
+	DefaultOutboxMaxBackoff   = 5 * time.Minute
+	DefaultOutboxPollInterval = time.Second
+)
+
+// OutboxItem holds a single pending request of an Outbox
+type OutboxItem struct {
+	// Unique identifier of the item
+	ID string `json:"id"`
+	// Items with the same key replace each other, so only the latest request
+	// of a link (or of a slashtag for creates) is sent
+	Key string `json:"key"`
+	// The pending request, encoded by EncodeRequest
+	Request Request `json:"-"`
+	// Number of attempts to send the request so far
+	Attempts int `json:"attempts"`
+	// UTC date/time the item was first queued
+	CreatedAt Timestamp `json:"createdAt"`
+	// UTC date/time of the next attempt
+	NextAttemptAt Timestamp `json:"nextAttemptAt"`
+	// The error of the last attempt
+	LastError string `json:"lastError,omitempty"`
+}
+
+// outboxItemJSON is the JSON format of OutboxItem
+type outboxItemJSON struct {
+	outboxItemFields
+	Request json.RawMessage `json:"request"`
+}
+
+// outboxItemFields has the fields of OutboxItem without its JSON methods
+type outboxItemFields OutboxItem
+
+// MarshalJSON encodes item, with its request encoded by EncodeRequest
+func (item OutboxItem) MarshalJSON() ([]byte, error) {
+	request, err := EncodeRequest(item.Request)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(outboxItemJSON{
+		outboxItemFields: outboxItemFields(item),
+		Request:          request,
+	})
+}
+
+// UnmarshalJSON decodes item, with its request decoded by DecodeRequest
+func (item *OutboxItem) UnmarshalJSON(data []byte) error {
+	var decoded outboxItemJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	request, err := DecodeRequest(decoded.Request)
+	if err != nil {
+		return err
+	}
+	*item = OutboxItem(decoded.outboxItemFields)
+	item.Request = request
+	return nil
+}
+
+// OutboxStore persists the pending items of an Outbox
+type OutboxStore interface {
+	// Put inserts item, or replaces the item with the same ID
+	Put(item OutboxItem) error
+	// Get returns the item of id, and whether it exists
+	Get(id string) (OutboxItem, bool, error)
+	// Delete removes the item of id, if it exists
+	Delete(id string) error
+	// List returns all the items
+	List() ([]OutboxItem, error)
+}
+
+// FileOutboxStore is an OutboxStore that keeps each item as a JSON file at
+// a directory. It is safe for concurrent use by a single process.
+type FileOutboxStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileOutboxStore returns a FileOutboxStore at dir, creating dir if needed
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileOutboxStore{Dir: dir}, nil
+}
+
+func (s *FileOutboxStore) itemPath(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+// Put implements the OutboxStore interface.
+// The item is written to a temporary file that is renamed into place, so a
+// crash never leaves a partial item behind.
+func (s *FileOutboxStore) Put(item OutboxItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp, err := ioutil.TempFile(s.Dir, item.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.itemPath(item.ID))
+}
+
+// Get implements the OutboxStore interface
+func (s *FileOutboxStore) Get(id string) (OutboxItem, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var item OutboxItem
+	data, err := ioutil.ReadFile(s.itemPath(id))
+	if os.IsNotExist(err) {
+		return item, false, nil
+	}
+	if err != nil {
+		return item, false, err
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, false, fmt.Errorf("Outbox item %s: %v", id, err)
+	}
+	return item, true, nil
+}
+
+// Delete implements the OutboxStore interface
+func (s *FileOutboxStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.itemPath(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List implements the OutboxStore interface
+func (s *FileOutboxStore) List() ([]OutboxItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var items []OutboxItem
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.Dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var item OutboxItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("Outbox item %s: %v", file.Name(), err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// OutboxQueuedError is returned by Outbox.Send when the request could not be
+// sent right away, and was queued for a later retry
+type OutboxQueuedError struct {
+	Item OutboxItem
+	// The error of the first attempt
+	Err error
+}
+
+func (e OutboxQueuedError) Error() string {
+	return fmt.Sprintf("Request was queued for retry: %v", e.Err)
+}
+
+// OutboxEnqueueError is returned by Outbox.Send when the request could
+// neither be sent nor queued
+type OutboxEnqueueError struct {
+	// The error of the first attempt
+	Err error
+	// The error of the store
+	EnqueueErr error
+}
+
+func (e OutboxEnqueueError) Error() string {
+	return fmt.Sprintf("Request failed: %v, and could not be queued: %v",
+		e.Err, e.EnqueueErr)
+}
+
+// Unwrap returns the error of the first attempt
+func (e OutboxEnqueueError) Unwrap() error {
+	return e.Err
+}
+
+// Outbox persists create, update and delete requests of links that could
+// not be sent due to server errors, and retries them in the background with
+// an exponential backoff.
+//
+// A pending item is superseded, and dropped without calling any callback,
+// when a newer request with the same key is queued, or is sent successfully
+// by Send. When the pending item is already being sent at that time, its
+// success or permanent failure is still reported, but it is never retried.
+type Outbox struct {
+	// The Sender for the requests
+	Sender Sender
+	// Where pending items are kept
+	Store OutboxStore
+	// Attempts before an item permanently fails, DefaultOutboxMaxAttempts
+	// when 0
+	MaxAttempts int
+	// Backoff after the first failed attempt, doubled on each attempt up to
+	// MaxBackoff. DefaultOutboxMinBackoff and DefaultOutboxMaxBackoff when 0
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// How often Run looks for due items, DefaultOutboxPollInterval when 0
+	PollInterval time.Duration
+	// When not nil, called after an item was sent successfully
+	OnSuccess func(item OutboxItem, answer interface{})
+	// When not nil, called after an item failed permanently
+	OnFailure func(item OutboxItem, err error)
+
+	// mu serializes the changes of the store
+	mu sync.Mutex
+	// processing allows a single ProcessDue at a time
+	processing sync.Mutex
+}
+
+// outboxKey returns the deduplication key of r, or an empty key when r
+// cannot be deduplicated. Updates and deletes are keyed by their link, and
+// creates by their slashtag.
+func outboxKey(r Request) string {
+	if r.ActionType != ActionTypeLinkCreate {
+		return string(r.ActionType) + ":" + r.URL.Path
+	}
+
+	var domainID, slashTag string
+	switch operation := r.Operation.(type) {
+	case LinkRequest:
+		domainID, slashTag = operation.Domain.ID, operation.SlashTag
+	case LinkCreateInput:
+		slashTag = operation.SlashTag
+		if operation.Domain != nil {
+			domainID = operation.Domain.ID
+		}
+	}
+	if slashTag == "" {
+		return ""
+	}
+	return string(r.ActionType) + ":" + linkKey(domainID, slashTag)
+}
+
+// isRetryable returns true for errors that might succeed on a later attempt:
+// server errors, rate limits and connection errors
+func isRetryable(err error) bool {
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+	switch e := err.(type) {
+	case ServerErrorResponse:
+		return true
+	case ResponseError:
+		return e.StatusCode == http.StatusTooManyRequests ||
+			e.StatusCode >= http.StatusInternalServerError
+	case *url.Error:
+		return e.Err != context.Canceled && e.Err != context.DeadlineExceeded
+	case net.Error:
+		return true
+	}
+	return false
+}
+
+// Enqueue persists r for a later attempt.
+//
+// Only link create, update and delete requests are accepted. A pending item
+// with the same action and link (or slashtag for creates) is superseded by r,
+// which keeps its place in the queue.
+func (o *Outbox) Enqueue(r Request) (OutboxItem, error) {
+	switch r.ActionType {
+	case ActionTypeLinkCreate, ActionTypeLinkUpdate, ActionTypeLinkDelete:
+	default:
+		return OutboxItem{}, fmt.Errorf("Unsupported outbox action: %q",
+			r.ActionType)
+	}
+
+	now := NewTimestamp(time.Now().UTC())
+	item := OutboxItem{
+		ID:            randomHex(8),
+		Key:           outboxKey(r),
+		Request:       r,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending, err := o.pendingByKey(item.Key)
+	if err != nil {
+		return OutboxItem{}, err
+	}
+	for _, old := range pending {
+		if old.CreatedAt.Time.Before(item.CreatedAt.Time) {
+			item.CreatedAt = old.CreatedAt
+		}
+	}
+	if err := o.Store.Put(item); err != nil {
+		return OutboxItem{}, err
+	}
+	return item, o.deleteItems(pending)
+}
+
+// pendingByKey returns the pending items of key, or none for an empty key.
+// o.mu must be held.
+func (o *Outbox) pendingByKey(key string) ([]OutboxItem, error) {
+	if key == "" {
+		return nil, nil
+	}
+	items, err := o.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	var pending []OutboxItem
+	for _, item := range items {
+		if item.Key == key {
+			pending = append(pending, item)
+		}
+	}
+	return pending, nil
+}
+
+// deleteItems removes items from the store. o.mu must be held.
+func (o *Outbox) deleteItems(items []OutboxItem) error {
+	for _, item := range items {
+		if err := o.Store.Delete(item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// supersede removes the pending items that have the key of r, after r was
+// sent successfully
+func (o *Outbox) supersede(r Request) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending, err := o.pendingByKey(outboxKey(r))
+	if err != nil {
+		return err
+	}
+	return o.deleteItems(pending)
+}
+
+// Send implements the Sender interface.
+//
+// The request is sent right away, and supersedes the pending items of the
+// same key when it succeeds. When it fails with a 5xx or 429 response, or a
+// connection error, it is queued, and OutboxQueuedError is returned, or
+// OutboxEnqueueError when it could not be queued. Other errors are returned
+// as is.
+func (o *Outbox) Send(ctx context.Context, r Request) (interface{}, error) {
+	answer, err := o.Sender.Send(ctx, r)
+	if err == nil {
+		if err := o.supersede(r); err != nil {
+			return answer, err
+		}
+		return answer, nil
+	}
+	if !isRetryable(err) {
+		return answer, err
+	}
+	item, queueErr := o.Enqueue(r)
+	if queueErr != nil {
+		return nil, OutboxEnqueueError{Err: err, EnqueueErr: queueErr}
+	}
+	return nil, OutboxQueuedError{Item: item, Err: err}
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	min, max := o.MinBackoff, o.MaxBackoff
+	if min <= 0 {
+		min = DefaultOutboxMinBackoff
+	}
+	if max <= 0 {
+		max = DefaultOutboxMaxBackoff
+	}
+	backoff := min
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// ProcessDue makes a single attempt for each of the items that are due, and
+// returns the number of items that were attempted.
+// Concurrent calls are run one after the other.
+func (o *Outbox) ProcessDue(ctx context.Context) (int, error) {
+	o.processing.Lock()
+	defer o.processing.Unlock()
+
+	items, err := o.Store.List()
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Time.Before(items[j].CreatedAt.Time)
+	})
+
+	maxAttempts := o.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultOutboxMaxAttempts
+	}
+	attempted := 0
+	now := time.Now()
+	for _, item := range items {
+		if item.NextAttemptAt.Valid && item.NextAttemptAt.Time.After(now) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return attempted, err
+		}
+		attempted++
+
+		answer, err := o.Sender.Send(ctx, item.Request)
+		item.Attempts++
+		if err != nil {
+			item.LastError = err.Error()
+		}
+		retry := err != nil && isRetryable(err) && item.Attempts < maxAttempts
+		if err := o.complete(item, retry); err != nil {
+			return attempted, err
+		}
+		switch {
+		case err == nil:
+			if o.OnSuccess != nil {
+				o.OnSuccess(item, answer)
+			}
+		case !retry:
+			if o.OnFailure != nil {
+				o.OnFailure(item, err)
+			}
+		}
+	}
+	return attempted, nil
+}
+
+// complete updates the store after an attempt of item: the item is kept for
+// another attempt when retry is true, and removed otherwise. An item that was
+// superseded during the attempt is no longer at the store, and is left as is.
+func (o *Outbox) complete(item OutboxItem, retry bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok, err := o.Store.Get(item.ID); err != nil || !ok {
+		return err
+	}
+	if !retry {
+		return o.Store.Delete(item.ID)
+	}
+	item.NextAttemptAt = NewTimestamp(
+		time.Now().Add(o.backoff(item.Attempts)).UTC())
+	return o.Store.Put(item)
+}
+
+// Run processes due items every PollInterval, until ctx is done.
+// It returns the error of ctx, or the first error of the store.
+func (o *Outbox) Run(ctx context.Context) error {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultOutboxPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := o.ProcessDue(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
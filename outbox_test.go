@@ -0,0 +1,279 @@
+package rebrandly
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// outboxSender answers requests with the errors of errs in order, and
+// records the destination of every request it receives
+type outboxSender struct {
+	mu           sync.Mutex
+	errs         []error
+	destinations []string
+	// When not nil, every request waits on it before being answered
+	block chan struct{}
+	// When not nil, receives a value when a request arrives
+	started chan struct{}
+}
+
+func (s *outboxSender) Send(ctx context.Context, r Request) (interface{}, error) {
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if link, ok := r.Operation.(LinkRequest); ok {
+		s.destinations = append(s.destinations, link.Destination)
+	}
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return LinkRequest{ID: "link"}, nil
+}
+
+func (s *outboxSender) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.destinations...)
+}
+
+var errOutboxServer = ServerErrorResponse{Message: "Unavailable", StatusCode: 503}
+
+func newTestOutbox(t *testing.T, sender Sender) *Outbox {
+	store, err := NewFileOutboxStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Outbox{
+		Sender:     sender,
+		Store:      store,
+		MinBackoff: time.Nanosecond,
+		MaxBackoff: time.Nanosecond,
+	}
+}
+
+func updateRequest(t *testing.T, destination string) Request {
+	r, err := InitUpdateLink("link", destination, "promo", "Promo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func pendingDestinations(t *testing.T, o *Outbox) []string {
+	items, err := o.Store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var destinations []string
+	for _, item := range items {
+		destinations = append(destinations,
+			item.Request.Operation.(LinkRequest).Destination)
+	}
+	return destinations
+}
+
+func TestOutboxRetriesUntilSuccess(t *testing.T) {
+	sender := &outboxSender{errs: []error{errOutboxServer, errOutboxServer}}
+	o := newTestOutbox(t, sender)
+	var succeeded []OutboxItem
+	o.OnSuccess = func(item OutboxItem, answer interface{}) {
+		succeeded = append(succeeded, item)
+	}
+
+	_, err := o.Send(context.Background(), updateRequest(t, "https://new"))
+	if _, ok := err.(OutboxQueuedError); !ok {
+		t.Fatalf("Send: expected OutboxQueuedError, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Millisecond)
+		if _, err := o.ProcessDue(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(succeeded) != 1 || succeeded[0].Attempts != 2 {
+		t.Fatalf("OnSuccess: got %+v", succeeded)
+	}
+	if pending := pendingDestinations(t, o); len(pending) != 0 {
+		t.Errorf("Pending: got %v", pending)
+	}
+}
+
+func TestOutboxPermanentFailure(t *testing.T) {
+	notFound := NotFoundResponse{Message: "Not found", Code: ErrorCodeNotFound}
+	sender := &outboxSender{errs: []error{errOutboxServer, notFound}}
+	o := newTestOutbox(t, sender)
+	var failed []error
+	o.OnFailure = func(item OutboxItem, err error) {
+		failed = append(failed, err)
+	}
+
+	o.Send(context.Background(), updateRequest(t, "https://new"))
+	if _, err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0] != notFound {
+		t.Fatalf("OnFailure: got %v", failed)
+	}
+	if pending := pendingDestinations(t, o); len(pending) != 0 {
+		t.Errorf("Pending: got %v", pending)
+	}
+}
+
+func TestOutboxEnqueueDeduplicates(t *testing.T) {
+	o := newTestOutbox(t, &outboxSender{})
+	first, err := o.Enqueue(updateRequest(t, "https://old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := o.Enqueue(updateRequest(t, "https://new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("Expected a new ID for the replacement")
+	}
+	pending := pendingDestinations(t, o)
+	if len(pending) != 1 || pending[0] != "https://new" {
+		t.Errorf("Pending: got %v", pending)
+	}
+}
+
+func TestOutboxEnqueueKeepsOtherLinks(t *testing.T) {
+	o := newTestOutbox(t, &outboxSender{})
+	for _, linkID := range []string{"link1", "link2"} {
+		r, err := InitUpdateLink(linkID, "https://"+linkID, "promo", "Promo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.Enqueue(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pending := pendingDestinations(t, o)
+	sort.Strings(pending)
+	if len(pending) != 2 || pending[0] != "https://link1" ||
+		pending[1] != "https://link2" {
+		t.Errorf("Pending: got %v", pending)
+	}
+}
+
+func TestOutboxRetryableErrors(t *testing.T) {
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{errOutboxServer, true},
+		{ResponseError{StatusCode: http.StatusTooManyRequests}, true},
+		{ResponseError{StatusCode: http.StatusBadGateway}, true},
+		{ResponseError{StatusCode: http.StatusConflict}, false},
+		{&url.Error{Op: "Post", URL: "https://api", Err: errors.New("Connection reset")}, true},
+		{&url.Error{Op: "Post", URL: "https://api", Err: context.Canceled}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("Connection refused")}, true},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{NotFoundResponse{Code: ErrorCodeNotFound}, false},
+		{JournalError{Err: errors.New("Disk full")}, false},
+		{errors.New("Unexpected answer type"), false},
+	}
+	for _, test := range tests {
+		if retryable := isRetryable(test.err); retryable != test.retryable {
+			t.Errorf("%#v: got %t", test.err, retryable)
+		}
+	}
+}
+
+func TestOutboxSendSupersedesPending(t *testing.T) {
+	sender := &outboxSender{errs: []error{errOutboxServer}}
+	o := newTestOutbox(t, sender)
+
+	o.Send(context.Background(), updateRequest(t, "https://old"))
+	if _, err := o.Send(context.Background(), updateRequest(t, "https://new")); err != nil {
+		t.Fatal(err)
+	}
+	if pending := pendingDestinations(t, o); len(pending) != 0 {
+		t.Fatalf("Pending: got %v", pending)
+	}
+	if _, err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sent := sender.sent()
+	if sent[len(sent)-1] != "https://new" {
+		t.Errorf("Sent: got %v", sent)
+	}
+}
+
+func TestOutboxEnqueueDuringAttempt(t *testing.T) {
+	for _, attemptErr := range []error{nil, errOutboxServer} {
+		sender := &outboxSender{
+			errs:    []error{attemptErr},
+			block:   make(chan struct{}),
+			started: make(chan struct{}),
+		}
+		o := newTestOutbox(t, sender)
+		if _, err := o.Enqueue(updateRequest(t, "https://v1")); err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan error)
+		go func() {
+			_, err := o.ProcessDue(context.Background())
+			done <- err
+		}()
+		<-sender.started
+		if _, err := o.Enqueue(updateRequest(t, "https://v2")); err != nil {
+			t.Fatal(err)
+		}
+		close(sender.block)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+
+		pending := pendingDestinations(t, o)
+		if len(pending) != 1 || pending[0] != "https://v2" {
+			t.Errorf("Attempt error %v: pending %v", attemptErr, pending)
+		}
+	}
+}
+
+// failingOutboxStore is an OutboxStore that cannot persist items
+type failingOutboxStore struct{}
+
+var errOutboxStore = errors.New("Disk full")
+
+func (failingOutboxStore) Put(item OutboxItem) error { return errOutboxStore }
+func (failingOutboxStore) Get(id string) (OutboxItem, bool, error) {
+	return OutboxItem{}, false, nil
+}
+func (failingOutboxStore) Delete(id string) error      { return nil }
+func (failingOutboxStore) List() ([]OutboxItem, error) { return nil, nil }
+
+func TestOutboxSendEnqueueError(t *testing.T) {
+	o := &Outbox{
+		Sender: &outboxSender{errs: []error{errOutboxServer}},
+		Store:  failingOutboxStore{},
+	}
+	_, err := o.Send(context.Background(), updateRequest(t, "https://new"))
+	enqueueErr, ok := err.(OutboxEnqueueError)
+	if !ok {
+		t.Fatalf("Send: expected OutboxEnqueueError, got %v", err)
+	}
+	if enqueueErr.Err != errOutboxServer || enqueueErr.EnqueueErr != errOutboxStore {
+		t.Errorf("Send: got %+v", enqueueErr)
+	}
+}
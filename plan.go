@@ -0,0 +1,392 @@
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// DefaultDomainName is the domain that rebrandly uses when no domain is given
+const DefaultDomainName = "rebrand.ly"
+
+// DesiredLink holds the desired state of a single link.
+//
+// Title, Favourite and ForwardParameters are managed only when they are set,
+// otherwise the value that is found on the live link is kept.
+type DesiredLink struct {
+	// Full name of the branded domain, empty for rebrand.ly
+	Domain string `json:"domain" yaml:"domain"`
+	// The keyword section of the branded short link
+	SlashTag string `json:"slashtag" yaml:"slashtag"`
+	// The destination URL of the branded short link
+	Destination string `json:"destination" yaml:"destination"`
+	// The title of the branded short link
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Whether the link is favourited (loved) or not
+	Favourite *bool `json:"favourite,omitempty" yaml:"favourite,omitempty"`
+	// Whether query parameters are forwarded to the destination URL
+	ForwardParameters *bool `json:"forwardParameters,omitempty" yaml:"forwardParameters,omitempty"`
+}
+
+// DomainName returns the full name of the domain of the link
+func (l DesiredLink) DomainName() string {
+	if l.Domain == "" {
+		return DefaultDomainName
+	}
+	return l.Domain
+}
+
+// DesiredState holds the desired state of the links of an account.
+//
+// JSON example for such state
+//
+//	{
+//	  "links": [
+//	    {
+//	      "domain": "brand.cool",
+//	      "slashtag": "promo",
+//	      "destination": "https://example.com/promo",
+//	      "title": "Promotion",
+//	      "favourite": true,
+//	      "forwardParameters": true
+//	    }
+//	  ]
+//	}
+type DesiredState struct {
+	Links []DesiredLink `json:"links" yaml:"links"`
+}
+
+// UnmarshalFunc is the signature of json.Unmarshal, and of most YAML packages
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// ReadDesiredState reads and validates a desired state from r.
+//
+// The content is decoded by unmarshal, or by json.Unmarshal when nil, so YAML
+// files are supported by passing the Unmarshal function of a YAML package.
+func ReadDesiredState(r io.Reader, unmarshal UnmarshalFunc) (DesiredState, error) {
+	var state DesiredState
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return state, err
+	}
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	if err := unmarshal(data, &state); err != nil {
+		return state, err
+	}
+
+	seen := make(map[string]bool)
+	for i, link := range state.Links {
+		if link.SlashTag == "" {
+			return state, fmt.Errorf("Link #%d: missing slashtag", i)
+		}
+		if link.Destination == "" {
+			return state, fmt.Errorf("Link #%d: missing destination", i)
+		}
+		key := linkKey(link.DomainName(), link.SlashTag)
+		if seen[key] {
+			return state, fmt.Errorf("Link #%d: duplicate link %s", i, key)
+		}
+		seen[key] = true
+	}
+	return state, nil
+}
+
+// PlanAction holds an "enum" of the actions of a plan
+type PlanAction string
+
+// Enumeration values for PlanAction
+const (
+	PlanActionCreate PlanAction = "create"
+	PlanActionUpdate PlanAction = "update"
+	PlanActionDelete PlanAction = "delete"
+	PlanActionNoOp   PlanAction = "noop"
+)
+
+// FieldChange holds a change of a single field of a link
+type FieldChange struct {
+	// JSON name of the field
+	Field string `json:"field"`
+	// The live value
+	Old string `json:"old"`
+	// The desired value
+	New string `json:"new"`
+}
+
+// PlanItem holds the action that is needed for a single link
+type PlanItem struct {
+	Action PlanAction `json:"action"`
+	// Full name of the domain of the link
+	Domain string `json:"domain"`
+	// ID and Ref of the domain of the link, empty for rebrand.ly. They are
+	// resolved by MakePlan, so a saved plan can be applied later.
+	DomainID  string `json:"domainId,omitempty"`
+	DomainRef string `json:"domainRef,omitempty"`
+	// The keyword section of the link
+	SlashTag string `json:"slashtag"`
+	// The desired state, empty for PlanActionDelete
+	Desired DesiredLink `json:"desired"`
+	// The live link, empty for PlanActionCreate
+	Live LinkRequest `json:"live"`
+	// The changed fields for PlanActionUpdate
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Plan holds the actions that are needed to bring the live account into the
+// desired state
+type Plan struct {
+	Items []PlanItem `json:"items"`
+}
+
+// HasChanges returns true when at least one item of the plan is not a no-op
+func (p Plan) HasChanges() bool {
+	for _, item := range p.Items {
+		if item.Action != PlanActionNoOp {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDiff writes a human readable diff of the plan into w.
+// No-op items are not written.
+func (p Plan) WriteDiff(w io.Writer) error {
+	var buf bytes.Buffer
+	for _, item := range p.Items {
+		name := item.Domain + "/" + item.SlashTag
+		switch item.Action {
+		case PlanActionCreate:
+			fmt.Fprintf(&buf, "+ %s -> %s\n", name, item.Desired.Destination)
+		case PlanActionDelete:
+			fmt.Fprintf(&buf, "- %s -> %s\n", name, item.Live.Destination)
+		case PlanActionUpdate:
+			fmt.Fprintf(&buf, "~ %s\n", name)
+			for _, change := range item.Changes {
+				fmt.Fprintf(&buf, "    %s: %q => %q\n", change.Field, change.Old,
+					change.New)
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// compareLink returns the fields that differ between the desired and the live
+// link
+func compareLink(desired DesiredLink, live LinkRequest) []FieldChange {
+	var changes []FieldChange
+	if desired.Destination != live.Destination {
+		changes = append(changes, FieldChange{
+			Field: "destination",
+			Old:   live.Destination,
+			New:   desired.Destination,
+		})
+	}
+	if desired.Title != "" && desired.Title != live.Title {
+		changes = append(changes, FieldChange{
+			Field: "title",
+			Old:   live.Title,
+			New:   desired.Title,
+		})
+	}
+	if desired.Favourite != nil && *desired.Favourite != live.Favourite {
+		changes = append(changes, FieldChange{
+			Field: "favourite",
+			Old:   strconv.FormatBool(live.Favourite),
+			New:   strconv.FormatBool(*desired.Favourite),
+		})
+	}
+	if desired.ForwardParameters != nil &&
+		*desired.ForwardParameters != live.ForwardParameters {
+		changes = append(changes, FieldChange{
+			Field: "forwardParameters",
+			Old:   strconv.FormatBool(live.ForwardParameters),
+			New:   strconv.FormatBool(*desired.ForwardParameters),
+		})
+	}
+	return changes
+}
+
+// MakePlan fetches the live links and domains of the account and computes the
+// actions that are needed to bring it into state.
+//
+// Only the domains that are referred by state are managed, and live active
+// links of these domains that are not part of state are planned for deletion.
+func MakePlan(ctx context.Context, sender Sender, state DesiredState) (Plan, error) {
+	var plan Plan
+	domains := make(map[string]DomainRequest)
+	err := WalkAllDomains(ctx, sender, func(domain DomainRequest) error {
+		domains[domain.FullName] = domain
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	managed := make(map[string]bool)
+	for _, link := range state.Links {
+		managed[link.DomainName()] = true
+	}
+
+	live := make(map[string]LinkRequest)
+	err = WalkAllLinks(ctx, sender, "", func(link LinkRequest) error {
+		if link.Status == LinkStatusTrashed || !managed[link.Domain.FullName] {
+			return nil
+		}
+		live[linkKey(link.Domain.FullName, link.SlashTag)] = link
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	for _, desired := range state.Links {
+		key := linkKey(desired.DomainName(), desired.SlashTag)
+		item := PlanItem{
+			Action:   PlanActionCreate,
+			Domain:   desired.DomainName(),
+			SlashTag: desired.SlashTag,
+			Desired:  desired,
+		}
+		if domain, ok := domains[item.Domain]; ok {
+			item.DomainID, item.DomainRef = domain.ID, domain.Ref
+		}
+		if link, ok := live[key]; ok {
+			delete(live, key)
+			item.Live = link
+			item.Changes = compareLink(desired, link)
+			item.Action = PlanActionNoOp
+			if len(item.Changes) > 0 {
+				item.Action = PlanActionUpdate
+			}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	keys := make([]string, 0, len(live))
+	for key := range live {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		link := live[key]
+		plan.Items = append(plan.Items, PlanItem{
+			Action:    PlanActionDelete,
+			Domain:    link.Domain.FullName,
+			DomainID:  link.Domain.ID,
+			DomainRef: link.Domain.Ref,
+			SlashTag:  link.SlashTag,
+			Live:      link,
+		})
+	}
+	return plan, nil
+}
+
+// ApplyOptions holds the options for ApplyPlan
+type ApplyOptions struct {
+	// Do not send any request. The results hold the items that would be
+	// applied, and the diff is still written into Diff.
+	DryRun bool
+	// When not nil, the diff of the plan is written into it
+	Diff io.Writer
+	// Delete links permanently instead of moving them into the trash
+	DeletePermanently bool
+}
+
+// ApplyResult holds the outcome of applying a single item of a plan
+type ApplyResult struct {
+	Item PlanItem
+	// The link as returned by rebrandly
+	Link LinkRequest
+	// The error that caused the item to fail
+	Err error
+}
+
+// ApplyPlan executes the items of plan using the create, update and delete
+// requests. No-op items are skipped.
+//
+// On a dry run, the requests of the items are built but not sent, so the
+// results hold the items that would be applied, with the errors of the items
+// that could not be applied.
+//
+// Failure of a single item does not stop the rest of the items, and is
+// reported at its ApplyResult.
+func ApplyPlan(ctx context.Context, sender Sender, plan Plan,
+	options ApplyOptions) ([]ApplyResult, error) {
+
+	if options.Diff != nil {
+		if err := plan.WriteDiff(options.Diff); err != nil {
+			return nil, err
+		}
+	}
+	var results []ApplyResult
+	for _, item := range plan.Items {
+		if item.Action == PlanActionNoOp {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		request, err := planItemRequest(item, options)
+		result := ApplyResult{Item: item, Err: err}
+		if err == nil && !options.DryRun {
+			var answer interface{}
+			answer, err = sender.Send(ctx, request)
+			if err != nil {
+				result.Err = err
+			} else if link, ok := answer.(LinkRequest); ok {
+				result.Link = link
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// planItemRequest creates the request that applies item
+func planItemRequest(item PlanItem, options ApplyOptions) (Request, error) {
+	switch item.Action {
+	case PlanActionCreate:
+		input := LinkCreateInput{
+			Title:             item.Desired.Title,
+			SlashTag:          item.Desired.SlashTag,
+			Destination:       item.Desired.Destination,
+			Favourite:         item.Desired.Favourite,
+			ForwardParameters: item.Desired.ForwardParameters,
+		}
+		if item.DomainID != "" {
+			input.Domain = &LinkDomainInput{
+				ID:       item.DomainID,
+				Ref:      item.DomainRef,
+				FullName: item.Domain,
+			}
+		} else if item.Domain != DefaultDomainName {
+			return Request{}, fmt.Errorf("Unknown domain: %s", item.Domain)
+		}
+		return InitCreateLinkInput(input)
+
+	case PlanActionUpdate:
+		fields := item.Live
+		fields.Destination = item.Desired.Destination
+		if item.Desired.Title != "" {
+			fields.Title = item.Desired.Title
+		}
+		if item.Desired.Favourite != nil {
+			fields.Favourite = *item.Desired.Favourite
+		}
+		if item.Desired.ForwardParameters != nil {
+			fields.ForwardParameters = *item.Desired.ForwardParameters
+		}
+		return InitUpdateLinkFrom(fields)
+
+	case PlanActionDelete:
+		return InitDeleteLink(item.Live.ID, !options.DeletePermanently)
+	}
+	return Request{}, fmt.Errorf("Unsupported plan action: %q", item.Action)
+}
@@ -0,0 +1,176 @@
+package rebrandly
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// requestJSONVersion is the version of the JSON format of EncodeRequest
+const requestJSONVersion = 1
+
+// OperationType holds an "enum" of the types of Request.Operation, as they are
+// named by the JSON format of EncodeRequest
+type OperationType string
+
+// Enumeration values for OperationType
+const (
+	OperationTypeNone       OperationType = ""
+	OperationTypeLink       OperationType = "link"
+	OperationTypeLinkCreate OperationType = "linkcreate"
+	OperationTypeLinkUpdate OperationType = "linkupdate"
+	// Operations of any other type, that are kept as their JSON, and decoded
+	// into json.RawMessage
+	OperationTypeRaw OperationType = "raw"
+)
+
+// requestJSON is the JSON format of EncodeRequest
+//
+// JSON example for such request
+//
+//	{
+//	  "version": 1,
+//	  "method": "POST",
+//	  "url": "https://api.rebrandly.com/v1/links",
+//	  "actionType": "linkcreate",
+//	  "operationType": "linkcreate",
+//	  "operation": {
+//	    "destination": "https://www.youtube.com/watch?v=x53JHab2ng8",
+//	    "slashtag": "gophers"
+//	  }
+//	}
+type requestJSON struct {
+	Version       int           `json:"version"`
+	Method        string        `json:"method"`
+	URL           string        `json:"url"`
+	ActionType    ActionTypes   `json:"actionType"`
+	OperationType OperationType `json:"operationType,omitempty"`
+	// Whether the operation is a pointer to its struct
+	Pointer   bool            `json:"pointer,omitempty"`
+	Operation json.RawMessage `json:"operation,omitempty"`
+	Header    http.Header     `json:"header,omitempty"`
+}
+
+// operationType returns the OperationType of operation, and whether it is a
+// pointer
+func operationType(operation interface{}) (OperationType, bool) {
+	switch operation.(type) {
+	case nil:
+		return OperationTypeNone, false
+	case LinkRequest:
+		return OperationTypeLink, false
+	case *LinkRequest:
+		return OperationTypeLink, true
+	case LinkCreateInput:
+		return OperationTypeLinkCreate, false
+	case *LinkCreateInput:
+		return OperationTypeLinkCreate, true
+	case LinkUpdateInput:
+		return OperationTypeLinkUpdate, false
+	case *LinkUpdateInput:
+		return OperationTypeLinkUpdate, true
+	}
+	return OperationTypeRaw, false
+}
+
+// decodeOperation decodes data into the struct of operationType (or a
+// pointer to it), or into the struct that is used by the builders of
+// actionType when operationType is empty
+func decodeOperation(operationType OperationType, pointer bool,
+	actionType ActionTypes, data []byte) (interface{}, error) {
+
+	if operationType == OperationTypeNone {
+		switch actionType {
+		case ActionTypeLinkCreate, ActionTypeLinkUpdate:
+			operationType = OperationTypeLink
+		default:
+			return nil, fmt.Errorf("Action %q does not have an operation",
+				actionType)
+		}
+	}
+
+	var operation interface{}
+	switch operationType {
+	case OperationTypeLink:
+		operation = &LinkRequest{}
+	case OperationTypeLinkCreate:
+		operation = &LinkCreateInput{}
+	case OperationTypeLinkUpdate:
+		operation = &LinkUpdateInput{}
+	case OperationTypeRaw:
+		return json.RawMessage(append([]byte(nil), data...)), nil
+	default:
+		return nil, fmt.Errorf("Unsupported operation type: %q", operationType)
+	}
+	if err := json.Unmarshal(data, operation); err != nil {
+		return nil, err
+	}
+	if pointer {
+		return operation, nil
+	}
+	return reflect.ValueOf(operation).Elem().Interface(), nil
+}
+
+// EncodeRequest encodes r using a stable JSON format, that holds the type of
+// the operation, so r can be stored (e.g. at a job queue) and decoded back by
+// DecodeRequest.
+//
+// Operations of the link structs (and pointers to them) are decoded back into
+// the same type. Operations of any other type are decoded as json.RawMessage,
+// which is sent with the same body.
+//
+// Note: Headers of r are encoded as is, including sensitive ones.
+func EncodeRequest(r Request) ([]byte, error) {
+	opType, pointer := operationType(r.Operation)
+	data := requestJSON{
+		Version:       requestJSONVersion,
+		Method:        r.Method,
+		URL:           r.URL.String(),
+		ActionType:    r.ActionType,
+		OperationType: opType,
+		Pointer:       pointer,
+		Header:        r.Header,
+	}
+	if r.Operation != nil {
+		var err error
+		data.Operation, err = json.Marshal(r.Operation)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(data)
+}
+
+// DecodeRequest decodes a Request from the JSON format of EncodeRequest,
+// decoding the operation back into its original type
+func DecodeRequest(data []byte) (Request, error) {
+	var decoded requestJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return Request{}, err
+	}
+	if decoded.Version > requestJSONVersion {
+		return Request{}, fmt.Errorf("Unsupported request version: %d",
+			decoded.Version)
+	}
+	u, err := url.Parse(decoded.URL)
+	if err != nil {
+		return Request{}, err
+	}
+
+	request := Request{
+		Method:     decoded.Method,
+		URL:        *u,
+		ActionType: decoded.ActionType,
+		Header:     decoded.Header,
+	}
+	if len(decoded.Operation) > 0 && string(decoded.Operation) != "null" {
+		request.Operation, err = decodeOperation(decoded.OperationType,
+			decoded.Pointer, decoded.ActionType, decoded.Operation)
+		if err != nil {
+			return Request{}, err
+		}
+	}
+	return request, nil
+}
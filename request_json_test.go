@@ -0,0 +1,112 @@
+package rebrandly
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// withOperation returns r with operation as its Operation
+func withOperation(r Request, operation interface{}) Request {
+	r.Operation = operation
+	return r
+}
+
+func TestEncodeRequestRoundTrip(t *testing.T) {
+	must := func(r Request, err error) Request {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	link := LinkRequest{Destination: "https://example.com", SlashTag: "promo"}
+	create := LinkCreateInput{
+		Destination: "https://example.com",
+		SlashTag:    "promo",
+		Domain:      &LinkDomainInput{ID: "domain"},
+		Favourite:   Bool(false),
+	}
+	update := LinkUpdateInput{
+		Destination: "https://example.com",
+		SlashTag:    "promo",
+		Title:       "Promo",
+		Favourite:   Bool(true),
+	}
+	createRequest := must(InitCreateLinkEx(link))
+	updateRequest := must(InitUpdateLinkEx("link", link))
+
+	tests := map[string]Request{
+		"create link":          createRequest,
+		"create link pointer":  withOperation(createRequest, &link),
+		"create input":         must(InitCreateLinkInput(create)),
+		"create input pointer": withOperation(createRequest, &create),
+		"update link":          updateRequest,
+		"update link pointer":  withOperation(updateRequest, &link),
+		"update input":         must(InitUpdateLinkInput("link", update)),
+		"update input pointer": withOperation(updateRequest, &update),
+		"raw":                  withOperation(updateRequest, json.RawMessage(`{"title":"Raw"}`)),
+		"delete":               must(InitDeleteLink("link", true)),
+		"details":              must(InitLinkDetails("link")),
+		"list": must(InitListLinks(true, "active", "domain",
+			OrderPagination{OrderBy: "createdAt", Limit: 5})),
+		"count":          must(InitLinkCount(false, "", "")),
+		"domain details": must(InitDomainDetails("domain")),
+		"domain list":    must(InitDomainList(true, "user", OrderPagination{Offset: 10})),
+		"domain count":   must(InitDomainCount(true, "")),
+		"header":         withHeader(must(InitLinkDetails("link")), "workspace", "ws"),
+	}
+	for name, r := range tests {
+		data, err := EncodeRequest(r)
+		if err != nil {
+			t.Errorf("%s: EncodeRequest: %v", name, err)
+			continue
+		}
+		decoded, err := DecodeRequest(data)
+		if err != nil {
+			t.Errorf("%s: DecodeRequest: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r, decoded) {
+			t.Errorf("%s: got %#v, expected %#v", name, decoded, r)
+		}
+	}
+}
+
+func TestEncodeRequestUnknownOperation(t *testing.T) {
+	r, err := InitLinkDetails("link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Operation = map[string]string{"title": "Map"}
+	data, err := EncodeRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeRequest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, ok := decoded.Operation.(json.RawMessage)
+	if !ok || string(raw) != `{"title":"Map"}` {
+		t.Errorf("Operation: got %#v", decoded.Operation)
+	}
+}
+
+func TestRequestDefaultJSON(t *testing.T) {
+	r, err := InitUpdateLinkEx("link", LinkRequest{Destination: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Operation = &LinkRequest{}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["Operation"]; !ok {
+		t.Errorf("Expected the default JSON of Request, got %s", data)
+	}
+}
@@ -1,6 +1,10 @@
 package rebrandly
 
-import "net/url"
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
 
 const (
 	contentType     = "application/json"
@@ -45,6 +49,30 @@ type Request struct {
 	ActionType ActionTypes
 	// The struct for the operation to be made
 	Operation interface{}
+	// Extra HTTP headers, replacing the default headers of the same name
+	Header http.Header
+}
+
+// Sender is the interface for anything that is able to send a Request to
+// rebrandly, and return the answer exactly as SendRequest does
+type Sender interface {
+	Send(ctx context.Context, r Request) (interface{}, error)
+}
+
+// APIKey is a Sender that sends requests directly to rebrandly using the key
+type APIKey string
+
+// Send implements the Sender interface using SendRequestContext
+func (k APIKey) Send(ctx context.Context, r Request) (interface{}, error) {
+	return r.SendRequestContext(ctx, string(k))
+}
+
+// SenderFunc is a function that implements the Sender interface
+type SenderFunc func(ctx context.Context, r Request) (interface{}, error)
+
+// Send implements the Sender interface
+func (f SenderFunc) Send(ctx context.Context, r Request) (interface{}, error) {
+	return f(ctx, r)
 }
 
 // OrderDirType is an enum string type
@@ -57,6 +85,18 @@ const (
 	OrderDirTypeNone OrderDirType = ""
 )
 
+// LinkFilter holds the filters that are used by InitListLinksFilter
+type LinkFilter struct {
+	// Favourite links only (or non favourite links only)
+	Favourite bool
+	// Status of the links, empty for the API default
+	Status LinkStatus
+	// Domain ID of the links, empty for all domains
+	DomainID string
+	// Slashtag of the links, empty for all slashtags
+	SlashTag string
+}
+
 // OrderPagination holds fields to help create list actions for order
 // and for pagination
 type OrderPagination struct {
@@ -1,11 +1,15 @@
 package rebrandly
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 func statusCodeToStruct(r Request, statusCode int, body []byte) (result interface{}, err error) {
@@ -47,19 +51,30 @@ func statusCodeToStruct(r Request, statusCode int, body []byte) (result interfac
 		if err == nil {
 			err = notFound
 		}
-	case http.StatusInternalServerError,
-		http.StatusBadGateway,
-		http.StatusServiceUnavailable,
-		http.StatusGatewayTimeout:
-
-		var serverErr ServerErrorResponse
-		err = json.Unmarshal(body, &serverErr)
-		if err == nil {
-			err = serverErr
+	default:
+		if statusCode >= http.StatusInternalServerError {
+			// Gateways in front of rebrandly might answer with a body that
+			// is not JSON
+			serverErr := ServerErrorResponse{StatusCode: statusCode}
+			if json.Unmarshal(body, &serverErr) != nil || serverErr.Message == "" {
+				serverErr.Message = strings.TrimSpace(string(body))
+			}
+			if serverErr.Message == "" {
+				serverErr.Message = http.StatusText(statusCode)
+			}
+			return nil, serverErr
 		}
+		err = fmt.Errorf("Unsupported StatusCode: %d", statusCode)
+	}
 
-	default:
-		return nil, fmt.Errorf("Unsupported StatusCode: %d", statusCode)
+	// Errors that are not REST errors, such as a body that is not JSON, keep
+	// the status code of the response
+	if err != nil && StatusCode(err) == 0 {
+		err = ResponseError{
+			StatusCode: statusCode,
+			Body:       string(body),
+			Err:        err,
+		}
 	}
 	return
 }
@@ -114,3 +129,38 @@ func orderAndPaginationURL(u *url.URL, orderPagination OrderPagination) {
 	q.Add("offset", strconv.FormatUint(orderPagination.Offset, 10))
 	u.RawQuery = q.Encode()
 }
+
+// httpRequest creates the HTTP request of r, and returns it together with its
+// JSON body
+func (r Request) httpRequest(ctx context.Context, apiKey string) (*http.Request, []byte, error) {
+	var reader io.Reader
+	var structToJSON []byte
+	var err error
+	if r.Operation != nil {
+		structToJSON, err = json.Marshal(r.Operation)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		reader = bytes.NewReader(structToJSON)
+	}
+	var req *http.Request
+	if len(structToJSON) > 0 {
+		req, err = http.NewRequest(r.Method, r.URL.String(), reader)
+	} else {
+		req, err = http.NewRequest(r.Method, r.URL.String(), nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-Type", contentType)
+	req.Header.Add("apikey", apiKey)
+	for name, values := range r.Header {
+		req.Header.Del(name)
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	return req, structToJSON, nil
+}
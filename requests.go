@@ -1,10 +1,8 @@
 package rebrandly
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -33,6 +31,23 @@ func InitCreateLinkEx(fields LinkRequest) (Request, error) {
 	return request, nil
 }
 
+// InitCreateLinkInput initialize the Request struct with parameters for
+// creating a link.
+// Only the fields that are set at input are sent to rebrandly.
+func InitCreateLinkInput(input LinkCreateInput) (Request, error) {
+	url, err := url.Parse(requestCreateLinks)
+	if err != nil {
+		return Request{}, err
+	}
+	request := Request{
+		Method:     http.MethodPost,
+		URL:        *url,
+		ActionType: ActionTypeLinkCreate,
+		Operation:  input,
+	}
+	return request, nil
+}
+
 // InitCreateLink initialize the Request struct with parameters for creating
 // a link.
 // The initialization is only with mandatory fileds.
@@ -68,6 +83,23 @@ func InitUpdateLinkEx(linkID string, fields LinkRequest) (Request, error) {
 	return request, nil
 }
 
+// InitUpdateLinkInput initialize the Request struct with parameters for
+// updating an existed link.
+// Only the fields that are set at input are sent to rebrandly.
+func InitUpdateLinkInput(linkID string, input LinkUpdateInput) (Request, error) {
+	url, err := url.Parse(fmt.Sprintf(requestUpdateLinks, linkID))
+	if err != nil {
+		return Request{}, err
+	}
+	request := Request{
+		Method:     http.MethodPost,
+		URL:        *url,
+		ActionType: ActionTypeLinkUpdate,
+		Operation:  input,
+	}
+	return request, nil
+}
+
 // InitUpdateLink changes the destination and/or slagTag of an existed link
 func InitUpdateLink(linkID, destination, slagTag, title string) (Request, error) {
 	return InitUpdateLinkEx(linkID, LinkRequest{
@@ -119,18 +151,33 @@ func InitLinkDetails(linkID string) (Request, error) {
 func InitListLinks(favorite bool, status, domainID string,
 	orderPagination OrderPagination) (Request, error) {
 
+	return InitListLinksFilter(LinkFilter{
+		Favourite: favorite,
+		Status:    LinkStatus(status),
+		DomainID:  domainID,
+	}, orderPagination)
+}
+
+// InitListLinksFilter initialize a request for a list of links based on
+// filter, order and pagination
+func InitListLinksFilter(filter LinkFilter,
+	orderPagination OrderPagination) (Request, error) {
+
 	url, err := url.Parse(requestListLinks)
 	if err != nil {
 		return Request{}, err
 	}
 	orderAndPaginationURL(url, orderPagination)
 	q := url.Query()
-	q.Add("favorite", strconv.FormatBool(favorite))
-	if status != "" {
-		q.Add("status", status)
+	q.Add("favorite", strconv.FormatBool(filter.Favourite))
+	if filter.Status != "" {
+		q.Add("status", string(filter.Status))
 	}
-	if domainID != "" {
-		q.Add("domain.id", domainID)
+	if filter.DomainID != "" {
+		q.Add("domain.id", filter.DomainID)
+	}
+	if filter.SlashTag != "" {
+		q.Add("slashtag", filter.SlashTag)
 	}
 	url.RawQuery = q.Encode()
 
@@ -240,29 +287,16 @@ func InitDomainCount(active bool, domainType string) (Request, error) {
 // If everything goes well, the return is the answer by the HTTP request
 // If there was internal issue, an error return
 func (r Request) SendRequest(apiKey string) (interface{}, error) {
-	var reader io.Reader
-	var structToJSON []byte
-	var err error
-	if r.Operation != nil {
-		structToJSON, err = json.Marshal(r.Operation)
-		if err != nil {
-			return nil, err
-		}
+	return r.SendRequestContext(context.Background(), apiKey)
+}
 
-		reader = bytes.NewReader(structToJSON)
-	}
-	client := &http.Client{}
-	var req *http.Request
-	if len(structToJSON) > 0 {
-		req, err = http.NewRequest(r.Method, r.URL.String(), reader)
-	} else {
-		req, err = http.NewRequest(r.Method, r.URL.String(), nil)
-	}
+// SendRequestContext is like SendRequest, but the HTTP request is bound to ctx
+func (r Request) SendRequestContext(ctx context.Context, apiKey string) (interface{}, error) {
+	req, _, err := r.httpRequest(ctx, apiKey)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Content-Type", contentType)
-	req.Header.Add("apikey", apiKey)
+	client := &http.Client{}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -0,0 +1,164 @@
+package rebrandly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RestoreAction holds an "enum" of the actions taken for a restored link
+type RestoreAction string
+
+// Enumeration values for RestoreAction
+const (
+	// The link was missing, and was created
+	RestoreActionCreated RestoreAction = "created"
+	// The link was missing, and would have been created if not for dry run
+	RestoreActionWouldCreate RestoreAction = "wouldcreate"
+	// A link with the same slashtag and destination already exists
+	RestoreActionSkipped RestoreAction = "skipped"
+	// A link with the same slashtag already exists, but with a different
+	// destination
+	RestoreActionConflict RestoreAction = "conflict"
+	// The link was not restored due to an error
+	RestoreActionFailed RestoreAction = "failed"
+)
+
+// RestoreOptions holds the options for RestoreLinks
+type RestoreOptions struct {
+	// Maps the domain IDs found at the export into domain IDs of the account
+	// that is being restored. Domains that are not mapped keep their ID.
+	DomainMap map[string]string
+	// Restore also links that were trashed at the time of the export
+	IncludeTrashed bool
+	// Report what would have been done, without creating any link
+	DryRun bool
+}
+
+// RestoreResult holds the outcome of restoring a single exported link
+type RestoreResult struct {
+	// What was done with the link
+	Action RestoreAction
+	// The link as it was read from the export
+	Link LinkRequest
+	// The link that already exists for skipped and conflicting links
+	Existing LinkRequest
+	// The link that was created
+	Created LinkRequest
+	// The error that caused the restore of the link to fail
+	Err error
+}
+
+func linkKey(domainID, slashTag string) string {
+	return domainID + "/" + slashTag
+}
+
+// ReadLinksJSONLines reads LinkRequest records written by ExportLinks using
+// ExportFormatJSONLines, and calls fn for each record.
+func ReadLinksJSONLines(r io.Reader, fn func(link LinkRequest) error) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var link LinkRequest
+		err := decoder.Decode(&link)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+}
+
+// RestoreLinks reads a JSON Lines export of links from r, and recreates the
+// links that are missing from the account of sender.
+//
+// A link is considered missing when there is no link with the same slashtag at
+// the (mapped) domain. Existing links are never modified; when their
+// destination differs from the exported one, they are reported as conflicts.
+//
+// The returned error is for failures that stopped the restore as a whole,
+// while failures of single links are reported at their RestoreResult.
+func RestoreLinks(ctx context.Context, sender Sender, r io.Reader,
+	options RestoreOptions) ([]RestoreResult, error) {
+
+	existing := make(map[string]LinkRequest)
+	err := WalkAllLinks(ctx, sender, "", func(link LinkRequest) error {
+		existing[linkKey(link.Domain.ID, link.SlashTag)] = link
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []RestoreResult
+	err = ReadLinksJSONLines(r, func(link LinkRequest) error {
+		if link.Status == LinkStatusTrashed && !options.IncludeTrashed {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		domainID := link.Domain.ID
+		if mapped, ok := options.DomainMap[domainID]; ok {
+			domainID = mapped
+		}
+		result := RestoreResult{Link: link}
+		key := linkKey(domainID, link.SlashTag)
+		if current, ok := existing[key]; ok {
+			result.Existing = current
+			result.Action = RestoreActionSkipped
+			if current.Destination != link.Destination {
+				result.Action = RestoreActionConflict
+			}
+			results = append(results, result)
+			return nil
+		}
+
+		if options.DryRun {
+			result.Action = RestoreActionWouldCreate
+			existing[key] = link
+			results = append(results, result)
+			return nil
+		}
+
+		created, err := restoreLink(ctx, sender, link, domainID)
+		if err != nil {
+			result.Action = RestoreActionFailed
+			result.Err = err
+		} else {
+			result.Action = RestoreActionCreated
+			result.Created = created
+			existing[key] = created
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+func restoreLink(ctx context.Context, sender Sender, link LinkRequest,
+	domainID string) (LinkRequest, error) {
+
+	input := LinkCreateInput{
+		Title:             link.Title,
+		SlashTag:          link.SlashTag,
+		Destination:       link.Destination,
+		Favourite:         Bool(link.Favourite),
+		ForwardParameters: Bool(link.ForwardParameters),
+	}
+	if domainID != "" {
+		input.Domain = &LinkDomainInput{
+			ID:  domainID,
+			Ref: fmt.Sprintf("/domains/%s", domainID),
+		}
+	}
+	request, err := InitCreateLinkInput(input)
+	if err != nil {
+		return LinkRequest{}, err
+	}
+	return sendLinkRequest(ctx, sender, request)
+}
@@ -0,0 +1,136 @@
+package rebrandly
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DestinationRewriter returns the new destination for destination, and
+// whether it was changed
+type DestinationRewriter func(destination string) (string, bool)
+
+// RegexpRewriter returns a DestinationRewriter that replaces the matches of re
+// with replacement, as regexp.ReplaceAllString does
+func RegexpRewriter(re *regexp.Regexp, replacement string) DestinationRewriter {
+	return func(destination string) (string, bool) {
+		rewritten := re.ReplaceAllString(destination, replacement)
+		return rewritten, rewritten != destination
+	}
+}
+
+// HostRewriter returns a DestinationRewriter that replaces the host of
+// destinations that are at oldHost with newHost, keeping the rest of the URL
+func HostRewriter(oldHost, newHost string) DestinationRewriter {
+	return func(destination string) (string, bool) {
+		u, err := url.Parse(destination)
+		if err != nil || !strings.EqualFold(u.Host, oldHost) {
+			return destination, false
+		}
+		u.Host = newHost
+		return u.String(), true
+	}
+}
+
+// RewriteOptions holds the filters of the links that are rewritten
+type RewriteOptions struct {
+	// Domain ID of the links, empty for all domains
+	DomainID string
+	// Status of the links, empty for all statuses
+	Status LinkStatus
+	// When not nil, only links with a matching title are rewritten
+	TitlePattern *regexp.Regexp
+}
+
+// RewriteChange holds a single planned change of destination
+type RewriteChange struct {
+	// The link before the change
+	Link LinkRequest
+	// The destination that is going to replace the current one
+	Destination string
+}
+
+// RewriteResult holds the outcome of applying a single RewriteChange
+type RewriteResult struct {
+	Change RewriteChange
+	// The link as returned by rebrandly after the update
+	Link LinkRequest
+	// The error that caused the update to fail
+	Err error
+}
+
+// PreviewRewrite walks over the links that match options, and returns the
+// changes that rewrite makes to their destinations, without updating them
+func PreviewRewrite(ctx context.Context, sender Sender,
+	rewrite DestinationRewriter, options RewriteOptions) ([]RewriteChange, error) {
+
+	var changes []RewriteChange
+	err := WalkLinksByStatus(ctx, sender, options.DomainID, options.Status,
+		func(link LinkRequest) error {
+			if options.TitlePattern != nil &&
+				!options.TitlePattern.MatchString(link.Title) {
+				return nil
+			}
+			destination, changed := rewrite(link.Destination)
+			if changed && destination != link.Destination {
+				changes = append(changes, RewriteChange{
+					Link:        link,
+					Destination: destination,
+				})
+			}
+			return nil
+		})
+	return changes, err
+}
+
+// WriteRewriteDiff writes a human readable diff of changes into w
+func WriteRewriteDiff(w io.Writer, changes []RewriteChange) error {
+	var buf bytes.Buffer
+	for _, change := range changes {
+		fmt.Fprintf(&buf, "~ %s\n", change.Link.ShortURL)
+		fmt.Fprintf(&buf, "    - %s\n", change.Link.Destination)
+		fmt.Fprintf(&buf, "    + %s\n", change.Destination)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ApplyRewrite updates the destination of each of changes, using up to
+// concurrency (DefaultBatchWorkers when 0) concurrent requests.
+//
+// The rest of the fields of each link, including its slashtag, title and
+// domain, are sent as they were when the change was previewed.
+// The results are at the same order as changes.
+func ApplyRewrite(ctx context.Context, sender Sender, changes []RewriteChange,
+	concurrency int) []RewriteResult {
+
+	results := make([]RewriteResult, len(changes))
+	var requests []Request
+	var indexes []int
+	for i, change := range changes {
+		results[i].Change = change
+		link := change.Link
+		link.Destination = change.Destination
+		request, err := InitUpdateLinkFrom(link)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		requests = append(requests, request)
+		indexes = append(indexes, i)
+	}
+
+	executor := BatchExecutor{Sender: sender, Workers: concurrency}
+	for _, batchResult := range executor.Run(ctx, requests) {
+		result := &results[indexes[batchResult.Index]]
+		result.Err = batchResult.Err
+		if link, ok := batchResult.Answer.(LinkRequest); ok {
+			result.Link = link
+		}
+	}
+	return results
+}
@@ -0,0 +1,235 @@
+package rebrandly
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// DefaultSlashTagAttempts is the number of candidates that are tried by
+// CreateLinkWithGenerator when no other number is given
+const DefaultSlashTagAttempts = 5
+
+// SlashTagGenerator generates candidate slashtags for a destination.
+// attempt starts at 0, and grows by one each time the previous candidate was
+// already taken, so a different candidate is expected for every attempt.
+type SlashTagGenerator interface {
+	SlashTag(destination string, attempt int) (string, error)
+}
+
+// SlashTagGeneratorFunc is a function that implements SlashTagGenerator
+type SlashTagGeneratorFunc func(destination string, attempt int) (string, error)
+
+// SlashTag implements the SlashTagGenerator interface
+func (f SlashTagGeneratorFunc) SlashTag(destination string, attempt int) (string, error) {
+	return f(destination, attempt)
+}
+
+// encodeBase62 returns n using the base62 alphabet
+func encodeBase62(n uint64) string {
+	if n == 0 {
+		return base62Alphabet[:1]
+	}
+	var buf []byte
+	for n > 0 {
+		buf = append([]byte{base62Alphabet[n%62]}, buf...)
+		n /= 62
+	}
+	return string(buf)
+}
+
+// destinationHash returns the hash of destination for the given attempt
+func destinationHash(destination string, attempt int) []byte {
+	if attempt > 0 {
+		destination = fmt.Sprintf("%s#%d", destination, attempt)
+	}
+	sum := sha256.Sum256([]byte(destination))
+	return sum[:]
+}
+
+// HashSlashTags generates slashtags from the hash of the destination, so the
+// same destination always yields the same slashtag
+type HashSlashTags struct {
+	// Length of the slashtag, default 7
+	Length int
+}
+
+// SlashTag implements the SlashTagGenerator interface.
+// A Length that is not between SlashTagMinLength and SlashTagMaxLength is
+// returned as an error.
+func (g HashSlashTags) SlashTag(destination string, attempt int) (string, error) {
+	length := g.Length
+	if length == 0 {
+		length = 7
+	}
+	if length < SlashTagMinLength || length > SlashTagMaxLength {
+		return "", fmt.Errorf("Invalid slashtag length: %d", length)
+	}
+	hash := destinationHash(destination, attempt)
+	var buf []byte
+	for i := 0; len(buf) < length; i++ {
+		n := binary.BigEndian.Uint64(hash[(i*8)%len(hash):][:8])
+		buf = append(buf, encodeBase62(n)...)
+	}
+	slashTag := string(buf[:length])
+	return slashTag, ValidateSlashTag(slashTag)
+}
+
+// DefaultWords is the default list of words for WordSlashTags
+var DefaultWords = []string{
+	"amber", "apple", "arrow", "bloom", "brave", "breeze", "cedar", "cloud",
+	"coral", "crisp", "daisy", "delta", "ember", "fable", "fern", "flame",
+	"frost", "glade", "gold", "grove", "harbor", "hazel", "honey", "iris",
+	"ivory", "jade", "jolly", "kite", "lemon", "lilac", "lunar", "maple",
+	"meadow", "mint", "noble", "nova", "oak", "ocean", "olive", "orbit",
+	"pearl", "pine", "plum", "quartz", "quest", "river", "robin", "ruby",
+	"sage", "solar", "spark", "stone", "swift", "tidal", "topaz", "tulip",
+	"umber", "vivid", "willow", "wind", "yarrow", "zephyr", "zest", "zinc",
+}
+
+// WordSlashTags generates human friendly slashtags from a list of words, that
+// are chosen by the hash of the destination
+type WordSlashTags struct {
+	// The words to choose from, DefaultWords when empty
+	Words []string
+	// How many words at each slashtag, default 2
+	Count int
+	// The string between the words, default "-"
+	Separator string
+}
+
+// SlashTag implements the SlashTagGenerator interface.
+// A negative Count, or one with more words than can fit in SlashTagMaxLength
+// even when the shortest word is chosen, is returned as an error.
+func (g WordSlashTags) SlashTag(destination string, attempt int) (string, error) {
+	words := g.Words
+	if len(words) == 0 {
+		words = DefaultWords
+	}
+	count := g.Count
+	if count == 0 {
+		count = 2
+	}
+	separator := g.Separator
+	if separator == "" {
+		separator = "-"
+	}
+	shortest := len(words[0])
+	for _, word := range words {
+		if len(word) < shortest {
+			shortest = len(word)
+		}
+	}
+	// count words take at least count*(shortest+len(separator))-len(separator)
+	// characters
+	maxCount := (SlashTagMaxLength + len(separator)) / (shortest + len(separator))
+	if count < 0 || count > maxCount {
+		return "", fmt.Errorf("Invalid slashtag word count: %d", count)
+	}
+	hash := destinationHash(destination, attempt)
+	chosen := make([]string, count)
+	for i := range chosen {
+		n := binary.BigEndian.Uint16(hash[(i*2)%len(hash):][:2])
+		chosen[i] = words[int(n)%len(words)]
+	}
+	slashTag := strings.Join(chosen, separator)
+	return slashTag, ValidateSlashTag(slashTag)
+}
+
+// Base62SlashTags generates slashtags from an incrementing counter, encoded
+// using base62. It is safe for concurrent use.
+type Base62SlashTags struct {
+	// Added before the counter
+	Prefix string
+
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewBase62SlashTags returns a Base62SlashTags that starts counting at start
+func NewBase62SlashTags(prefix string, start uint64) *Base62SlashTags {
+	return &Base62SlashTags{Prefix: prefix, next: start}
+}
+
+// SlashTag implements the SlashTagGenerator interface.
+// Every call, including each attempt, consumes the next value of the counter.
+func (g *Base62SlashTags) SlashTag(destination string, attempt int) (string, error) {
+	g.mu.Lock()
+	n := g.next
+	g.next++
+	g.mu.Unlock()
+
+	slashTag := g.Prefix + encodeBase62(n)
+	for len(slashTag) < SlashTagMinLength {
+		slashTag = g.Prefix + base62Alphabet[:1] + slashTag[len(g.Prefix):]
+	}
+	return slashTag, ValidateSlashTag(slashTag)
+}
+
+// DateSlashTags generates slashtags from a prefix and the current date, such
+// as promo-20170824, adding a counter for attempts after the first one
+type DateSlashTags struct {
+	// Added before the date
+	Prefix string
+	// Layout of the date, default "20060102"
+	Layout string
+	// Returns the current time, default time.Now
+	Now func() time.Time
+}
+
+// SlashTag implements the SlashTagGenerator interface
+func (g DateSlashTags) SlashTag(destination string, attempt int) (string, error) {
+	layout := g.Layout
+	if layout == "" {
+		layout = "20060102"
+	}
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	slashTag := g.Prefix + now().UTC().Format(layout)
+	if attempt > 0 {
+		slashTag = fmt.Sprintf("%s-%d", slashTag, attempt+1)
+	}
+	return slashTag, ValidateSlashTag(slashTag)
+}
+
+// CreateLinkWithGenerator creates a link from input, with the slashtag set by
+// generator. When the slashtag is already taken, a new candidate is generated
+// and the creation is retried, up to attempts times (DefaultSlashTagAttempts
+// when 0).
+//
+// Candidates that are not valid slashtags are returned as an error without
+// being sent.
+func CreateLinkWithGenerator(ctx context.Context, sender Sender,
+	input LinkCreateInput, generator SlashTagGenerator,
+	attempts int) (LinkRequest, error) {
+
+	if attempts <= 0 {
+		attempts = DefaultSlashTagAttempts
+	}
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		input.SlashTag, err = generator.SlashTag(input.Destination, attempt)
+		if err != nil {
+			return LinkRequest{}, err
+		}
+		var request Request
+		request, err = InitCreateLinkInput(input)
+		if err != nil {
+			return LinkRequest{}, err
+		}
+		var link LinkRequest
+		link, err = sendLinkRequest(ctx, sender, request)
+		if !IsAlreadyExists(err, "slashtag") {
+			return link, err
+		}
+	}
+	return LinkRequest{}, err
+}
@@ -0,0 +1,114 @@
+package rebrandly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timestampLayouts are the layouts that are tried, in order, when decoding a
+// Timestamp from a JSON string
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02",
+}
+
+// Timestamp holds a date/time returned by rebrandly, that might also be
+// missing.
+//
+// Rebrandly returns null or an empty string for dates that never happened,
+// such as the last click of a link that was never clicked. These, as well as
+// strings that could not be parsed, are decoded into a Timestamp that is not
+// Valid, rather than failing the whole answer. Such strings are kept as is, so
+// they are encoded back unchanged.
+type Timestamp struct {
+	// The date/time, in UTC when decoded from a JSON string
+	Time time.Time
+	// Whether Time holds an actual date/time
+	Valid bool
+
+	// The JSON string that was decoded into an invalid Timestamp
+	raw string
+}
+
+// NewTimestamp returns a valid Timestamp of t
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t, Valid: true}
+}
+
+// IsZero returns true when t is not valid, or holds the zero time
+func (t Timestamp) IsZero() bool {
+	return !t.Valid || t.Time.IsZero()
+}
+
+// String returns the date/time using RFC3339, or an empty string when t is
+// not valid
+func (t Timestamp) String() string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// MarshalJSON encodes t as an RFC3339 string. When t is not valid, the string
+// it was decoded from is encoded, or null when there is none.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		if t.raw != "" {
+			return []byte(t.raw), nil
+		}
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time)
+}
+
+// UnmarshalJSON decodes t from null, a string using one of the supported
+// layouts, or a number of seconds (or milliseconds) since the Unix epoch.
+// Other JSON types are rejected.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	*t = Timestamp{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if data[0] != '"' {
+		epoch, err := strconv.ParseFloat(string(data), 64)
+		if err != nil {
+			return fmt.Errorf("Unsupported timestamp: %s", data)
+		}
+		// Values that are too large for seconds are milliseconds
+		if epoch > 1e11 || epoch < -1e11 {
+			epoch /= 1000
+		}
+		sec := int64(epoch)
+		nsec := int64((epoch - float64(sec)) * 1e9)
+		*t = NewTimestamp(time.Unix(sec, nsec).UTC())
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.raw = string(data)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range timestampLayouts {
+		parsed, err := time.Parse(layout, value)
+		if err == nil {
+			*t = NewTimestamp(parsed.UTC())
+			return nil
+		}
+	}
+	return nil
+}
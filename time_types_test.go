@@ -0,0 +1,112 @@
+package rebrandly
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+		time  time.Time
+		// The expected JSON when encoding the decoded value back
+		output string
+	}{
+		{
+			name:   "null",
+			input:  `null`,
+			output: `null`,
+		},
+		{
+			name:   "empty string",
+			input:  `""`,
+			output: `""`,
+		},
+		{
+			name:   "RFC3339",
+			input:  `"2016-07-13T10:54:12.000Z"`,
+			valid:  true,
+			time:   time.Date(2016, 7, 13, 10, 54, 12, 0, time.UTC),
+			output: `"2016-07-13T10:54:12Z"`,
+		},
+		{
+			name:   "RFC3339 with offset",
+			input:  `"2016-07-13T12:54:12+02:00"`,
+			valid:  true,
+			time:   time.Date(2016, 7, 13, 10, 54, 12, 0, time.UTC),
+			output: `"2016-07-13T10:54:12Z"`,
+		},
+		{
+			name:   "epoch seconds",
+			input:  `1468407252`,
+			valid:  true,
+			time:   time.Date(2016, 7, 13, 10, 54, 12, 0, time.UTC),
+			output: `"2016-07-13T10:54:12Z"`,
+		},
+		{
+			name:   "epoch milliseconds",
+			input:  `1468407252000`,
+			valid:  true,
+			time:   time.Date(2016, 7, 13, 10, 54, 12, 0, time.UTC),
+			output: `"2016-07-13T10:54:12Z"`,
+		},
+		{
+			name:   "unparseable string",
+			input:  `"13/07/2016"`,
+			output: `"13/07/2016"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ts Timestamp
+			if err := json.Unmarshal([]byte(test.input), &ts); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", test.input, err)
+			}
+			if ts.Valid != test.valid {
+				t.Errorf("Valid = %v, want %v", ts.Valid, test.valid)
+			}
+			if test.valid && !ts.Time.Equal(test.time) {
+				t.Errorf("Time = %v, want %v", ts.Time, test.time)
+			}
+
+			output, err := json.Marshal(ts)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(output) != test.output {
+				t.Errorf("Marshal = %s, want %s", output, test.output)
+			}
+		})
+	}
+}
+
+func TestTimestampUnmarshalJSONInvalidType(t *testing.T) {
+	for _, input := range []string{`true`, `false`, `{}`, `[]`} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(input), &ts); err == nil {
+			t.Errorf("Unmarshal(%s): expected an error", input)
+		}
+	}
+}
+
+func TestTimestampInStruct(t *testing.T) {
+	var link struct {
+		CreatedAt   Timestamp `json:"createdAt"`
+		LastClickAt Timestamp `json:"lastClickAt"`
+	}
+	input := `{"createdAt":"13/07/2016","lastClickAt":null}`
+	if err := json.Unmarshal([]byte(input), &link); err != nil {
+		t.Fatal(err)
+	}
+	output, err := json.Marshal(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != input {
+		t.Errorf("Marshal = %s, want %s", output, input)
+	}
+}
@@ -0,0 +1,108 @@
+package rebrandly
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceParentHeader is the W3C trace context header
+const TraceParentHeader = "traceparent"
+
+// Span is a single traced request, as started by a Tracer
+type Span interface {
+	// SetAttribute records an attribute of the span
+	SetAttribute(key string, value interface{})
+	// TraceParent returns the W3C traceparent of the span, that is sent to
+	// rebrandly. An empty value sends the traceparent of the context instead.
+	TraceParent() string
+	// End ends the span, with the error of the request, if any
+	End(err error)
+}
+
+// Tracer starts spans. It is a small interface that can be implemented on top
+// of any tracing SDK.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of ctx that holds traceParent, to be
+// propagated to rebrandly by TracingMiddleware
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// TraceParentFromContext returns the traceparent that is held by ctx, or an
+// empty string
+func TraceParentFromContext(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentKey{}).(string)
+	return traceParent
+}
+
+// ValidTraceParent returns true when traceParent is a valid W3C traceparent,
+// such as 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ValidTraceParent(traceParent string) bool {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 {
+		return false
+	}
+	for i, length := range []int{2, 32, 16, 2} {
+		if len(parts[i]) != length {
+			return false
+		}
+		decoded, err := hex.DecodeString(parts[i])
+		if err != nil || strings.ToLower(parts[i]) != parts[i] {
+			return false
+		}
+		zero := true
+		for _, b := range decoded {
+			zero = zero && b == 0
+		}
+		// Trace and parent IDs must not be all zeros
+		if zero && (i == 1 || i == 2) {
+			return false
+		}
+	}
+	return parts[0] != "ff" && (parts[0] != "00" || len(parts) == 4)
+}
+
+// TracingMiddleware returns a Middleware that starts a span using tracer for
+// every request, named after its ActionType, and propagates the traceparent
+// header to rebrandly.
+//
+// The span holds the action, method, path, status and error code of the
+// request as attributes. When tracer is nil, only the traceparent of the
+// context is propagated.
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, r Request) (interface{}, error) {
+			var span Span
+			if tracer != nil {
+				ctx, span = tracer.Start(ctx, "rebrandly."+string(r.ActionType))
+				span.SetAttribute("rebrandly.action", string(r.ActionType))
+				span.SetAttribute("http.method", r.Method)
+				span.SetAttribute("url.path", r.URL.Path)
+			}
+
+			traceParent := TraceParentFromContext(ctx)
+			if span != nil && span.TraceParent() != "" {
+				traceParent = span.TraceParent()
+			}
+			if ValidTraceParent(traceParent) {
+				r = withHeader(r, TraceParentHeader, traceParent)
+			}
+
+			answer, err := next.Send(ctx, r)
+			if span != nil {
+				span.SetAttribute("http.status_code", StatusCode(err))
+				if code := ErrorCodeOf(err); code != "" {
+					span.SetAttribute("rebrandly.error_code", string(code))
+				}
+				span.End(err)
+			}
+			return answer, err
+		})
+	}
+}
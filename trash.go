@@ -0,0 +1,96 @@
+package rebrandly
+
+import (
+	"context"
+	"time"
+)
+
+// TrashResult holds the outcome of a trash operation on a single link
+type TrashResult struct {
+	// The link, as returned by rebrandly when the operation succeeded
+	Link LinkRequest
+	// The error that caused the operation to fail
+	Err error
+}
+
+// ListTrashedLinks returns the trashed links of domainID, or all domains when
+// empty
+func ListTrashedLinks(ctx context.Context, sender Sender, domainID string) (LinkRequestList, error) {
+	var trashed LinkRequestList
+	err := WalkLinksByStatus(ctx, sender, domainID, LinkStatusTrashed,
+		func(link LinkRequest) error {
+			trashed = append(trashed, link)
+			return nil
+		})
+	return trashed, err
+}
+
+// RestoreTrashedLink moves a trashed link back to LinkStatusActive, keeping
+// the rest of its fields
+func RestoreTrashedLink(ctx context.Context, sender Sender, linkID string) (LinkRequest, error) {
+	return UpdateLink(ctx, sender, linkID, SetStatus(LinkStatusActive))
+}
+
+// RestoreTrashedLinks moves each of linkIDs back to LinkStatusActive.
+// The results are at the same order as linkIDs.
+func RestoreTrashedLinks(ctx context.Context, sender Sender,
+	linkIDs []string) ([]TrashResult, error) {
+
+	results := make([]TrashResult, 0, len(linkIDs))
+	for _, linkID := range linkIDs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		link, err := RestoreTrashedLink(ctx, sender, linkID)
+		if err != nil {
+			link.ID = linkID
+		}
+		results = append(results, TrashResult{Link: link, Err: err})
+	}
+	return results, nil
+}
+
+// trashedAt returns the time a trashed link was moved into the trash, that is
+// the last time it was updated
+func trashedAt(link LinkRequest) time.Time {
+	if link.UpdatedAt.Valid {
+		return link.UpdatedAt.Time
+	}
+	return link.CreatedAt.Time
+}
+
+// PurgeTrash permanently deletes the trashed links of domainID (or all
+// domains when empty) that were moved into the trash more than olderThan ago.
+//
+// When dryRun is true, the links that would have been deleted are returned
+// without deleting them.
+func PurgeTrash(ctx context.Context, sender Sender, domainID string,
+	olderThan time.Duration, dryRun bool) ([]TrashResult, error) {
+
+	trashed, err := ListTrashedLinks(ctx, sender, domainID)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(-olderThan)
+	var results []TrashResult
+	for _, link := range trashed {
+		if !trashedAt(link).Before(deadline) {
+			continue
+		}
+		if dryRun {
+			results = append(results, TrashResult{Link: link})
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := TrashResult{Link: link}
+		request, err := InitDeleteLink(link.ID, false)
+		if err == nil {
+			_, err = sender.Send(ctx, request)
+		}
+		result.Err = err
+		results = append(results, result)
+	}
+	return results, nil
+}
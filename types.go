@@ -1,7 +1,5 @@
 package rebrandly
 
-import "time"
-
 // The following section holds the structure for All the requests and responces
 // that defined at the model section by Rebrandly documentation located at:
 // https://developers.rebrandly.com/docs/model-overview
@@ -43,9 +41,9 @@ type DomainRequest struct {
 	// The top level domain part of the branded domain name
 	TopLevelDomain string `json:"topLevelDomain"`
 	// UTC creation date/time of the branded domain
-	CreatedAt time.Time `json:"createdAt"`
+	CreatedAt Timestamp `json:"createdAt"`
 	// UTC last update date/time of the branded domain
-	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedAt Timestamp `json:"updatedAt"`
 	// Branded domain type
 	Type DomainTypes `json:"type"`
 	// Whether the branded domain can be used or not to create branded short links
@@ -121,14 +119,14 @@ type LinkRequest struct {
 	// Status of the branded short link.
 	Status LinkStatus `json:"status"`
 	// The UTC date/time this branded short link was created
-	CreatedAt time.Time `json:"createdAt"`
+	CreatedAt Timestamp `json:"createdAt"`
 	// The last UTC date/time this branded short link was updated.
 	// When created, it matches CreatedAt
-	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedAt Timestamp `json:"updatedAt"`
 	// How many clicks there are on this branded short link so far
 	Clicks int64 `json:"clicks"`
 	// The UTC date/time this branded short link was last clicked on
-	LastClickAt time.Time `json:"lastClickAt"`
+	LastClickAt Timestamp `json:"lastClickAt"`
 	// Whether a link is favourited (loved) or not
 	Favourite bool `json:"favourite"`
 	// Whether query parameters in short URL will be forwarded to destination URL.
@@ -174,10 +172,10 @@ type AccountSubscription struct {
 	// Category the account's plan belongs to
 	Category string `json:"category"`
 	// UTC subscription date/time of the account's current plan
-	CreatedAt time.Time `json:"createdAt"`
+	CreatedAt Timestamp `json:"createdAt"`
 	// UTC expiration date/time of the account's current plan, when plan's
 	// category is not free
-	ExpiredAt time.Time `json:"expiredAt"`
+	ExpiredAt Timestamp `json:"expiredAt"`
 	// Account's resources usage and limits: how many links/domains/tags/etc
 	// created so far and which are the maximum limits
 	Limits map[AccountLimitName]AccountLimit `json:"limits"`
@@ -232,7 +230,7 @@ type AccountRequest struct {
 	// URL of the account avatar
 	AvatarURL string `json:"avatarUrl"`
 	// UTC creation date/time of the account
-	CreatedAt time.Time `json:"createdAt"`
+	CreatedAt Timestamp `json:"createdAt"`
 	// Set of feature/limits info related to the account and its plan
 	Subscription AccountSubscription `json:"subscription"`
 }
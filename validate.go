@@ -0,0 +1,182 @@
+package rebrandly
+
+import (
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+)
+
+// Limits that are enforced by rebrandly on the fields of a link
+const (
+	SlashTagMinLength = 2
+	SlashTagMaxLength = 40
+	TitleMaxLength    = 255
+)
+
+// destinationPattern is the pattern that a destination has to match
+const destinationPattern = "^https?://.+"
+
+// isSlashTagChar returns true for characters that are allowed at a slashtag
+func isSlashTagChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') || c == '-' || c == '_'
+}
+
+// validator collects the validation errors of a single input
+type validator struct {
+	errors []InvalidFormatResponse
+}
+
+func (v *validator) add(err InvalidFormatResponse) {
+	v.errors = append(v.errors, err)
+}
+
+func (v *validator) required(property, value string) bool {
+	if value != "" {
+		return true
+	}
+	v.add(InvalidFormatResponse{
+		Message:  "Cannot be empty",
+		Code:     ErrorCodeRequiredField,
+		Property: property,
+	})
+	return false
+}
+
+func (v *validator) maxLength(property, value string, max uint64) {
+	if uint64(utf8.RuneCountInString(value)) <= max {
+		return
+	}
+	v.add(InvalidFormatResponse{
+		Message: fmt.Sprintf("Value cannot be more than %d characters long",
+			max),
+		Code:      ErrorCodeInvalidMaxLength,
+		Property:  property,
+		Input:     value,
+		MaxLength: max,
+	})
+}
+
+func (v *validator) destination(destination string) {
+	if !v.required("destination", destination) {
+		return
+	}
+	u, err := url.Parse(destination)
+	if err != nil || u.Host == "" {
+		v.add(InvalidFormatResponse{
+			Message:  "Invalid format",
+			Code:     ErrorCodeInvalidFormat,
+			Property: "destination",
+			Input:    destination,
+		})
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		v.add(InvalidFormatResponse{
+			Message:  "Value does not match the pattern",
+			Code:     ErrorCodePatternMismatch,
+			Property: "destination",
+			Input:    destination,
+			Pattern:  destinationPattern,
+		})
+	}
+}
+
+func (v *validator) slashTag(slashTag string) {
+	if slashTag == "" {
+		return
+	}
+	length := uint64(utf8.RuneCountInString(slashTag))
+	if length < SlashTagMinLength {
+		v.add(InvalidFormatResponse{
+			Message: fmt.Sprintf("Value cannot be less than %d characters long",
+				SlashTagMinLength),
+			Code:      ErrorCodeInvalidMinLength,
+			Property:  "slashtag",
+			Input:     slashTag,
+			MinLength: SlashTagMinLength,
+		})
+	}
+	v.maxLength("slashtag", slashTag, SlashTagMaxLength)
+	for _, c := range slashTag {
+		if !isSlashTagChar(c) {
+			v.add(InvalidFormatResponse{
+				Message:   "Invalid character",
+				Code:      ErrorCodeInvalidCharacter,
+				Property:  "slashtag",
+				Input:     slashTag,
+				Character: string(c),
+			})
+			break
+		}
+	}
+}
+
+func (v *validator) domain(domain *LinkDomainInput) {
+	if domain != nil {
+		v.required("domain.id", domain.ID)
+	}
+}
+
+// err returns nil when no error was found, the error itself when a single
+// error was found, or an InvalidFormatResponse holding all errors at its
+// Errors field
+func (v *validator) err() error {
+	switch len(v.errors) {
+	case 0:
+		return nil
+	case 1:
+		return v.errors[0]
+	}
+	result := InvalidFormatResponse{
+		Message: "Invalid format",
+		Code:    ErrorCodeInvalidFormat,
+	}
+	for _, err := range v.errors {
+		result.Errors = append(result.Errors, ErrorRequest{
+			Message:  err.Message,
+			Code:     err.Code,
+			Property: err.Property,
+		})
+	}
+	return result
+}
+
+// Validate checks the fields of input the same way rebrandly does, so
+// malformed input is found without sending it.
+//
+// The returned error is an InvalidFormatResponse with the same Code and
+// Property that rebrandly returns. When more than one field is invalid, the
+// errors are nested at its Errors field.
+func (input LinkCreateInput) Validate() error {
+	var v validator
+	v.destination(input.Destination)
+	v.slashTag(input.SlashTag)
+	v.maxLength("title", input.Title, TitleMaxLength)
+	v.domain(input.Domain)
+	return v.err()
+}
+
+// Validate checks the fields of input the same way rebrandly does, as
+// LinkCreateInput.Validate does, also requiring the slashtag and title.
+func (input LinkUpdateInput) Validate() error {
+	var v validator
+	v.destination(input.Destination)
+	if v.required("slashtag", input.SlashTag) {
+		v.slashTag(input.SlashTag)
+	}
+	if v.required("title", input.Title) {
+		v.maxLength("title", input.Title, TitleMaxLength)
+	}
+	v.domain(input.Domain)
+	return v.err()
+}
+
+// ValidateSlashTag checks a single slashtag the same way rebrandly does
+func ValidateSlashTag(slashTag string) error {
+	var v validator
+	if v.required("slashtag", slashTag) {
+		v.slashTag(slashTag)
+	}
+	return v.err()
+}
@@ -0,0 +1,182 @@
+package rebrandly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// listPageSize is the number of records asked for on each page while walking
+// over list requests
+const listPageSize uint64 = 25
+
+// ErrStopWalk can be returned by a walk callback in order to stop the walk
+// without reporting an error
+var ErrStopWalk = errors.New("Stop walk")
+
+// WalkLinks walks over every page of InitListLinksFilter based on filter, and
+// calls fn for each link found.
+//
+// Links are ordered by their creation time, so links that are created during
+// the walk do not shift the pages that are still ahead.
+func WalkLinks(ctx context.Context, sender Sender, filter LinkFilter,
+	fn func(link LinkRequest) error) error {
+
+	var offset uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		request, err := InitListLinksFilter(filter, OrderPagination{
+			OrderBy:  "createdAt",
+			OrderDir: OrderDirTypeAsc,
+			Offset:   offset,
+			Limit:    listPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		answer, err := sender.Send(ctx, request)
+		if err != nil {
+			return err
+		}
+		list, ok := answer.(LinkRequestList)
+		if !ok {
+			return fmt.Errorf("Unexpected answer type: %T", answer)
+		}
+		for _, link := range list {
+			if err := fn(link); err != nil {
+				if err == ErrStopWalk {
+					return nil
+				}
+				return err
+			}
+		}
+		if uint64(len(list)) < listPageSize {
+			return nil
+		}
+		offset += uint64(len(list))
+	}
+}
+
+// WalkAllLinks walks over all the links of domainID (or all domains when
+// empty), regardless of their status or being favourite, including trashed
+// links.
+// Every link is passed to fn only once.
+func WalkAllLinks(ctx context.Context, sender Sender, domainID string,
+	fn func(link LinkRequest) error) error {
+
+	return walkLinkStatuses(ctx, sender, LinkFilter{DomainID: domainID},
+		[]LinkStatus{LinkStatusActive, LinkStatusTrashed}, fn)
+}
+
+// WalkLinksByStatus walks over all the links of domainID (or all domains when
+// empty) that have status, regardless of being favourite.
+// When status is empty, it is the same as WalkAllLinks.
+func WalkLinksByStatus(ctx context.Context, sender Sender, domainID string,
+	status LinkStatus, fn func(link LinkRequest) error) error {
+
+	if status == "" {
+		return WalkAllLinks(ctx, sender, domainID, fn)
+	}
+	return walkLinkStatuses(ctx, sender, LinkFilter{DomainID: domainID},
+		[]LinkStatus{status}, fn)
+}
+
+// walkLinkStatuses walks over the links of filter with each of statuses, in
+// order, both favourite and not, passing every link to fn only once
+func walkLinkStatuses(ctx context.Context, sender Sender, filter LinkFilter,
+	statuses []LinkStatus, fn func(link LinkRequest) error) error {
+
+	seen := make(map[string]bool)
+	stopped := false
+	for _, status := range statuses {
+		for _, favourite := range []bool{false, true} {
+			filter.Favourite, filter.Status = favourite, status
+			err := WalkLinks(ctx, sender, filter, func(link LinkRequest) error {
+				if seen[link.ID] {
+					return nil
+				}
+				seen[link.ID] = true
+				err := fn(link)
+				if err == ErrStopWalk {
+					stopped = true
+				}
+				return err
+			})
+			if err != nil || stopped {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WalkDomains walks over every page of InitDomainList based on the filters,
+// and calls fn for each domain found.
+func WalkDomains(ctx context.Context, sender Sender, active bool,
+	domainType DomainTypes, fn func(domain DomainRequest) error) error {
+
+	var offset uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		request, err := InitDomainList(active, string(domainType),
+			OrderPagination{
+				OrderBy:  "createdAt",
+				OrderDir: OrderDirTypeAsc,
+				Offset:   offset,
+				Limit:    listPageSize,
+			})
+		if err != nil {
+			return err
+		}
+		answer, err := sender.Send(ctx, request)
+		if err != nil {
+			return err
+		}
+		list, ok := answer.(DomainRequestList)
+		if !ok {
+			return fmt.Errorf("Unexpected answer type: %T", answer)
+		}
+		for _, domain := range list {
+			if err := fn(domain); err != nil {
+				if err == ErrStopWalk {
+					return nil
+				}
+				return err
+			}
+		}
+		if uint64(len(list)) < listPageSize {
+			return nil
+		}
+		offset += uint64(len(list))
+	}
+}
+
+// WalkAllDomains walks over all the domains of the account, active or not.
+// Every domain is passed to fn only once.
+func WalkAllDomains(ctx context.Context, sender Sender,
+	fn func(domain DomainRequest) error) error {
+
+	seen := make(map[string]bool)
+	stopped := false
+	for _, active := range []bool{true, false} {
+		err := WalkDomains(ctx, sender, active, "", func(domain DomainRequest) error {
+			if seen[domain.ID] {
+				return nil
+			}
+			seen[domain.ID] = true
+			err := fn(domain)
+			if err == ErrStopWalk {
+				stopped = true
+			}
+			return err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}